@@ -0,0 +1,134 @@
+package concourse
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The OAuth client fly logs in with, every concourse since 4.0 knows it
+const (
+	flyClientId     = "fly"
+	flyClientSecret = "Zmx5"
+)
+
+// The scopes fly requests with the password grant
+const skyScope = "openid profile email federated:id groups"
+
+// Authentication token returned from concourse 3.x
+type Token struct {
+	// The type of the token, usually Bearer
+	Type string `json:"type"`
+
+	// The token itself
+	Value string `json:"value"`
+}
+
+// OAuth token returned from the sky endpoints of concourse 4.0 and later
+type skyToken struct {
+	TokenType   string `json:"token_type"`
+	AccessToken string `json:"access_token"`
+
+	// Concourse 7.x authorizes with the ID token
+	IdToken string `json:"id_token"`
+}
+
+// The major version of the concourse
+func (c *Client) majorVersion() (int, error) {
+	info, err := c.Info()
+	if err != nil {
+		return 0, err
+	}
+
+	major, err := strconv.Atoi(strings.SplitN(info.Version, ".", 2)[0])
+	if err != nil {
+		return 0, fmt.Errorf("Unknown concourse version %q", info.Version)
+	}
+	return major, nil
+}
+
+// Authenticates in the team with the user and the password of the concourse.
+// Concourse 3.x issues team tokens, 4.x-6.x issue tokens at /sky/token and 7.x and later at /sky/issuer/token.
+// Without user and password only the public parts of the API are accessible.
+func (c *Client) Login() error {
+	c.token = ""
+
+	if c.Concourse.User == "" || c.Concourse.Password == "" {
+		return nil
+	}
+
+	major, err := c.majorVersion()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case major < 4:
+		return c.teamLogin()
+	case major < 7:
+		return c.skyLogin("/sky/token")
+	default:
+		return c.skyLogin("/sky/issuer/token")
+	}
+}
+
+// Obtains a team token with basic authentication
+func (c *Client) teamLogin() error {
+	credentials := base64.StdEncoding.EncodeToString([]byte(c.Concourse.User + ":" + c.Concourse.Password))
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+credentials)
+
+	_, body, err := c.do("GET", c.teamPath("auth", "token"), header, nil, http.StatusOK)
+	if err != nil {
+		return err
+	}
+
+	token := &Token{}
+	err = json.Unmarshal(body, token)
+	if err != nil {
+		return err
+	}
+
+	c.token = token.Value
+	return nil
+}
+
+// Obtains a token with the OAuth password grant of the fly client
+func (c *Client) skyLogin(path string) error {
+	client := base64.StdEncoding.EncodeToString([]byte(flyClientId + ":" + flyClientSecret))
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+client)
+	header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	form := url.Values{}
+	form.Set("grant_type", "password")
+	form.Set("username", c.Concourse.User)
+	form.Set("password", c.Concourse.Password)
+	form.Set("scope", skyScope)
+
+	_, body, err := c.do("POST", path, header, []byte(form.Encode()), http.StatusOK)
+	if err != nil {
+		return err
+	}
+
+	token := &skyToken{}
+	err = json.Unmarshal(body, token)
+	if err != nil {
+		return err
+	}
+
+	c.token = token.IdToken
+	if c.token == "" {
+		c.token = token.AccessToken
+	}
+	return nil
+}
+
+// Uses already obtained token instead of login
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
@@ -0,0 +1,148 @@
+package concourse
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+)
+
+// The header concourse uses to version the pipeline configurations
+const ConfigVersionHeader = "X-Concourse-Config-Version"
+
+// The team used when the concourse does not specify one
+const DefaultTeam = "main"
+
+// Client of the concourse HTTP API
+type Client struct {
+	// The concourse to talk to
+	Concourse *primitive.Concourse
+
+	// The HTTP client used for the requests
+	HTTPClient *http.Client
+
+	token string
+}
+
+// A status returned from the API that is not expected
+type StatusError struct {
+	// The request method
+	Method string
+
+	// The request path
+	Path string
+
+	// The status code of the response
+	StatusCode int
+
+	// The body of the response
+	Body string
+}
+
+func (se *StatusError) Error() string {
+	return fmt.Sprintf("Unexpected status %d for %s %s: %s", se.StatusCode, se.Method, se.Path, se.Body)
+}
+
+func NewClient(concourse *primitive.Concourse) *Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+
+	if concourse.Insecure {
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
+		}
+	}
+
+	return &Client{
+		Concourse: concourse,
+		HTTPClient: &http.Client{
+			Transport: transport,
+		},
+	}
+}
+
+func (c *Client) team() string {
+	if c.Concourse.Team == "" {
+		return DefaultTeam
+	}
+	return c.Concourse.Team
+}
+
+func (c *Client) teamPath(elements ...string) string {
+	escaped := []string{"/api/v1/teams", url.PathEscape(c.team())}
+	for _, element := range elements {
+		escaped = append(escaped, url.PathEscape(element))
+	}
+	return strings.Join(escaped, "/")
+}
+
+func (c *Client) request(method string, path string, header http.Header, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	request, err := http.NewRequest(method, strings.TrimSuffix(c.Concourse.URL, "/")+path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return c.HTTPClient.Do(request)
+}
+
+// Executes the request and fails if the response status is not one of the expected
+func (c *Client) do(method string, path string, header http.Header, body []byte, expected ...int) (*http.Response, []byte, error) {
+	response, err := c.request(method, path, header, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, status := range expected {
+		if response.StatusCode == status {
+			return response, responseBody, nil
+		}
+	}
+
+	return nil, nil, &StatusError{
+		Method:     method,
+		Path:       path,
+		StatusCode: response.StatusCode,
+		Body:       string(responseBody),
+	}
+}
+
+func (c *Client) doJSON(method string, path string, result interface{}) error {
+	_, body, err := c.do(method, path, nil, nil, http.StatusOK)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, result)
+}
+
+func isNotFound(err error) bool {
+	statusError, ok := err.(*StatusError)
+	return ok && statusError.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,269 @@
+package concourse
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+type fakePipeline struct {
+	paused  bool
+	version int
+	config  []byte
+}
+
+// A stand-in for the concourse API of a single team
+type fakeConcourse struct {
+	team      string
+	version   string
+	pipelines map[string]*fakePipeline
+}
+
+// The sky token endpoint of the version of the fake, empty for 3.x
+func (fc *fakeConcourse) skyTokenPath() string {
+	switch fc.version[0] {
+	case '3':
+		return ""
+	case '7':
+		return "/sky/issuer/token"
+	default:
+		return "/sky/token"
+	}
+}
+
+func (fc *fakeConcourse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/v1/info" {
+		w.Write([]byte(`{"version":"` + fc.version + `","worker_version":"1.2"}`))
+		return
+	}
+
+	if path := fc.skyTokenPath(); path != "" && r.URL.Path == path {
+		client, secret, _ := r.BasicAuth()
+		if r.Method != "POST" || client != "fly" || secret != "Zmx5" || r.FormValue("grant_type") != "password" ||
+			r.FormValue("username") != "user" || r.FormValue("password") != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if fc.version[0] == '7' {
+			w.Write([]byte(`{"token_type":"bearer","access_token":"opaque","id_token":"token"}`))
+			return
+		}
+		w.Write([]byte(`{"token_type":"bearer","access_token":"token"}`))
+		return
+	}
+
+	teamPrefix := "/api/v1/teams/" + fc.team + "/"
+	if !strings.HasPrefix(r.URL.Path, teamPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, teamPrefix), "/")
+
+	if path[0] == "auth" && fc.skyTokenPath() == "" {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "user" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"type":"Bearer","value":"token"}`))
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if len(path) == 1 {
+		var pipelines []*Pipeline
+		for name, pipeline := range fc.pipelines {
+			pipelines = append(pipelines, &Pipeline{Name: name, Paused: pipeline.paused, TeamName: fc.team})
+		}
+		json.NewEncoder(w).Encode(pipelines)
+		return
+	}
+
+	name := path[1]
+	pipeline := fc.pipelines[name]
+
+	if len(path) == 2 && r.Method == "DELETE" && pipeline != nil {
+		delete(fc.pipelines, name)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if len(path) == 3 && path[2] == "config" && r.Method == "PUT" {
+		status := http.StatusOK
+		if pipeline == nil {
+			pipeline = &fakePipeline{paused: true}
+			fc.pipelines[name] = pipeline
+			status = http.StatusCreated
+		} else if r.Header.Get(ConfigVersionHeader) != strconv.Itoa(pipeline.version) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		pipeline.config, _ = ioutil.ReadAll(r.Body)
+		pipeline.version++
+		w.WriteHeader(status)
+		return
+	}
+
+	if pipeline == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(path) == 3 && path[2] == "config" && r.Method == "GET":
+		w.Header().Set(ConfigVersionHeader, strconv.Itoa(pipeline.version))
+		config := map[string]json.RawMessage{
+			"config": yamlToJSON(pipeline.config),
+		}
+		json.NewEncoder(w).Encode(config)
+	case len(path) == 3 && path[2] == "pause":
+		pipeline.paused = true
+	case len(path) == 3 && path[2] == "unpause":
+		pipeline.paused = false
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func yamlToJSON(config []byte) json.RawMessage {
+	var value map[string]interface{}
+	yaml.Unmarshal(config, &value)
+	result, _ := json.Marshal(value)
+	return result
+}
+
+func newTestClient(t *testing.T) (*Client, *fakeConcourse) {
+	fake := &fakeConcourse{
+		team:      "team",
+		version:   "3.5.0",
+		pipelines: make(map[string]*fakePipeline),
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := NewClient(&primitive.Concourse{
+		URL:      server.URL,
+		Team:     "team",
+		User:     "user",
+		Password: "password",
+	})
+
+	return client, fake
+}
+
+func TestClientInfo(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	info, err := client.Info()
+	require.NoError(t, err)
+	assert.Equal(t, "3.5.0", info.Version)
+
+	assert.NoError(t, client.CheckVersion("3.5.0"))
+	assert.Error(t, client.CheckVersion("3.4.1"))
+}
+
+func TestClientRequiresLogin(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	_, err := client.Pipelines()
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, err.(*StatusError).StatusCode)
+
+	client.Concourse.Password = "wrong"
+	assert.Error(t, client.Login())
+}
+
+func TestClientLoginVersions(t *testing.T) {
+	for _, version := range []string{"3.5.0", "5.8.1", "6.7.2", "7.4.0"} {
+		client, fake := newTestClient(t)
+		fake.version = version
+
+		require.NoError(t, client.Login(), version)
+		_, err := client.Pipelines()
+		assert.NoError(t, err, version)
+
+		client.Concourse.Password = "wrong"
+		assert.Error(t, client.Login(), version)
+	}
+
+	client, fake := newTestClient(t)
+	fake.version = "unknown"
+	assert.EqualError(t, client.Login(), "Unknown concourse version \"unknown\"")
+}
+
+func TestClientPipelines(t *testing.T) {
+	client, fake := newTestClient(t)
+	require.NoError(t, client.Login())
+
+	config, _, err := client.GetPipelineConfig("foo")
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	created, err := client.PutPipelineConfig("foo", "", []byte("jobs:\n- name: a\n"))
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	pipelines, err := client.Pipelines()
+	require.NoError(t, err)
+	require.Len(t, pipelines, 1)
+	assert.Equal(t, "foo", pipelines[0].Name)
+	assert.True(t, pipelines[0].Paused)
+
+	require.NoError(t, client.UnpausePipeline("foo"))
+	assert.False(t, fake.pipelines["foo"].paused)
+
+	require.NoError(t, client.PausePipeline("foo"))
+	assert.True(t, fake.pipelines["foo"].paused)
+
+	config, version, err := client.GetPipelineConfig("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "1", version)
+	assert.JSONEq(t, `{"jobs":[{"name":"a"}]}`, string(config))
+
+	_, err = client.PutPipelineConfig("foo", "0", []byte("jobs:\n- name: b\n"))
+	assert.Error(t, err)
+
+	require.NoError(t, client.SetPipelineConfig("foo", []byte("jobs:\n- name: b\n")))
+	config, err = client.PipelineConfig("foo")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jobs":[{"name":"b"}]}`, string(config))
+
+	require.NoError(t, client.DestroyPipeline("foo"))
+	assert.Empty(t, fake.pipelines)
+}
+
+func TestClientAsDeployTarget(t *testing.T) {
+	client, fake := newTestClient(t)
+	require.NoError(t, client.Login())
+
+	pipeline := project.NewPipeline()
+	pipeline.Name = "foo"
+	pipeline.Jobs = project.Jobs{
+		&project.Job{Name: "a"},
+	}
+
+	prj := &project.Project{
+		Pipelines: project.Pipelines{pipeline},
+	}
+
+	require.NoError(t, prj.Deploy("team", "installation", client))
+	assert.Equal(t, 1, fake.pipelines["foo"].version)
+
+	require.NoError(t, prj.Deploy("team", "installation", client))
+	assert.Equal(t, 1, fake.pipelines["foo"].version)
+}
@@ -0,0 +1,37 @@
+package concourse
+
+import (
+	"fmt"
+)
+
+// Information about the concourse installation
+type Info struct {
+	// The concourse version
+	Version string `json:"version"`
+
+	// The version of the worker protocol
+	WorkerVersion string `json:"worker_version"`
+}
+
+func (c *Client) Info() (*Info, error) {
+	info := &Info{}
+	err := c.doJSON("GET", "/api/v1/info", info)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Fails if the fly version does not match the concourse version
+func (c *Client) CheckVersion(flyVersion string) error {
+	info, err := c.Info()
+	if err != nil {
+		return err
+	}
+
+	if info.Version != flyVersion {
+		return fmt.Errorf("Fly version %s does not match concourse version %s", flyVersion, info.Version)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package concourse
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+// A pipeline as concourse lists it
+type Pipeline struct {
+	// The id of the pipeline
+	ID int `json:"id"`
+
+	// The name of the pipeline
+	Name string `json:"name"`
+
+	// Is the pipeline paused
+	Paused bool `json:"paused"`
+
+	// Is the pipeline visible without authentication
+	Public bool `json:"public"`
+
+	// Is the pipeline archived
+	Archived bool `json:"archived,omitempty"`
+
+	// The team the pipeline belongs to
+	TeamName string `json:"team_name"`
+}
+
+type pipelineConfig struct {
+	Config json.RawMessage `json:"config"`
+}
+
+// Lists the pipelines of the team
+func (c *Client) Pipelines() ([]*Pipeline, error) {
+	var pipelines []*Pipeline
+	err := c.doJSON("GET", c.teamPath("pipelines"), &pipelines)
+	if err != nil {
+		return nil, err
+	}
+	return pipelines, nil
+}
+
+// Returns the configuration of the pipeline as json and the version of the configuration.
+// If the pipeline does not exist the configuration is nil.
+func (c *Client) GetPipelineConfig(name string) ([]byte, string, error) {
+	response, body, err := c.do("GET", c.teamPath("pipelines", name, "config"), nil, nil, http.StatusOK)
+	if isNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	config := &pipelineConfig{}
+	err = json.Unmarshal(body, config)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return config.Config, response.Header.Get(ConfigVersionHeader), nil
+}
+
+// Sets the configuration of the pipeline. The version should be the one returned from GetPipelineConfig,
+// concourse rejects the configuration if the pipeline was changed in the mean time.
+// Returns true if the pipeline was created.
+func (c *Client) PutPipelineConfig(name string, version string, config []byte) (bool, error) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-yaml")
+	header.Set(ConfigVersionHeader, version)
+
+	response, _, err := c.do("PUT", c.teamPath("pipelines", name, "config"), header, config,
+		http.StatusOK, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		return false, err
+	}
+
+	return response.StatusCode == http.StatusCreated, nil
+}
+
+func (c *Client) DestroyPipeline(name string) error {
+	_, _, err := c.do("DELETE", c.teamPath("pipelines", name), nil, nil, http.StatusNoContent, http.StatusOK)
+	return err
+}
+
+func (c *Client) PausePipeline(name string) error {
+	_, _, err := c.do("PUT", c.teamPath("pipelines", name, "pause"), nil, nil, http.StatusOK)
+	return err
+}
+
+func (c *Client) UnpausePipeline(name string) error {
+	_, _, err := c.do("PUT", c.teamPath("pipelines", name, "unpause"), nil, nil, http.StatusOK)
+	return err
+}
+
+// Archives the pipeline, supported by concourse 6.5 and later
+func (c *Client) ArchivePipeline(name string) error {
+	_, _, err := c.do("PUT", c.teamPath("pipelines", name, "archive"), nil, nil, http.StatusOK)
+	return err
+}
+
+// Returns the configuration of the deployed pipeline, nil if the pipeline does not exist.
+// Together with SetPipelineConfig allows for the client to be a deploy target of a project.
+func (c *Client) PipelineConfig(name project.PipelineName) ([]byte, error) {
+	config, _, err := c.GetPipelineConfig(string(name))
+	return config, err
+}
+
+// Sets the configuration of the pipeline over its current version
+func (c *Client) SetPipelineConfig(name project.PipelineName, config []byte) error {
+	_, version, err := c.GetPipelineConfig(string(name))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.PutPipelineConfig(string(name), version, config)
+	return err
+}
@@ -0,0 +1,25 @@
+package deploy
+
+import (
+	"github.com/concourse-friends/concourse-builder/concourse"
+)
+
+// The API the reconciler uses to inspect and change the pipelines of a team.
+// concourse.Client implements it.
+type IBackend interface {
+	// Lists the pipelines of the team
+	Pipelines() ([]*concourse.Pipeline, error)
+
+	// Returns the configuration of the pipeline and its version, nil if the pipeline does not exist
+	GetPipelineConfig(name string) ([]byte, string, error)
+
+	// Sets the configuration of the pipeline over the version, returns true if the pipeline was created
+	PutPipelineConfig(name string, version string, config []byte) (bool, error)
+
+	PausePipeline(name string) error
+	UnpausePipeline(name string) error
+	ArchivePipeline(name string) error
+	DestroyPipeline(name string) error
+}
+
+var _ IBackend = &concourse.Client{}
@@ -0,0 +1,35 @@
+package deploy
+
+import (
+	"regexp"
+
+	"github.com/concourse-friends/concourse-builder/concourse"
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+// Owns the pipelines marked with the same generator as the owner
+type MarkerOwnership struct {
+	Owner *project.PipelineOwner
+
+	// Optional. The pipelines without any owner which names match are owned too. The pipelines deployed
+	// before they were marked have no owner, this way they are adopted and marked with their next update.
+	AdoptUnmarked *regexp.Regexp
+}
+
+func (mo *MarkerOwnership) Owns(pipeline *concourse.Pipeline, config []byte) bool {
+	if config == nil {
+		return false
+	}
+
+	owner, err := project.PipelineOwnerFrom(config)
+	if err != nil {
+		logger.Printf("Pipeline %s owner can not be read: %s", pipeline.Name, err.Error())
+		return false
+	}
+
+	if owner == nil {
+		return mo.AdoptUnmarked != nil && mo.AdoptUnmarked.MatchString(pipeline.Name)
+	}
+
+	return mo.Owner.Owns(owner)
+}
@@ -0,0 +1,69 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An action the reconciler takes over a pipeline
+type Action int
+
+const (
+	CreateAction Action = iota
+	UpdateAction
+	UnpauseAction
+	PauseAction
+	ArchiveAction
+	DestroyAction
+)
+
+func (a Action) String() string {
+	switch a {
+	case CreateAction:
+		return "create"
+	case UpdateAction:
+		return "update"
+	case UnpauseAction:
+		return "unpause"
+	case PauseAction:
+		return "pause"
+	case ArchiveAction:
+		return "archive"
+	case DestroyAction:
+		return "destroy"
+	}
+	return fmt.Sprintf("action(%d)", int(a))
+}
+
+// A single step of the plan
+type Step struct {
+	// What to do
+	Action Action
+
+	// The name of the pipeline
+	Pipeline string
+
+	// Why the step is needed
+	Reason string
+
+	// The configuration to set for create and update steps
+	Config []byte
+
+	// The version of the configuration to update
+	Version string
+}
+
+func (s *Step) String() string {
+	return fmt.Sprintf("%s %s: %s", s.Action, s.Pipeline, s.Reason)
+}
+
+// The steps needed for the team pipelines to converge to the project
+type Plan []*Step
+
+func (p Plan) String() string {
+	lines := make([]string, 0, len(p))
+	for _, step := range p {
+		lines = append(lines, step.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,274 @@
+package deploy
+
+import (
+	"log"
+	"os"
+	"sort"
+
+	"github.com/concourse-friends/concourse-builder/concourse"
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+var logger = log.New(os.Stdout, "", log.LstdFlags)
+
+// What to do with the owned pipelines that are not part of the project anymore
+type RemovalPolicy int
+
+const (
+	// Leave the pipelines as they are
+	KeepRemoved RemovalPolicy = iota
+
+	// Pause the pipelines
+	PauseRemoved
+
+	// Archive the pipelines
+	ArchiveRemoved
+
+	// Destroy the pipelines together with their build history
+	DestroyRemoved
+)
+
+// Decides which of the live pipelines the reconciler is allowed to remove
+type IOwnership interface {
+	Owns(pipeline *concourse.Pipeline, config []byte) bool
+}
+
+// Adapter of a function to the ownership interface
+type OwnershipFunc func(pipeline *concourse.Pipeline, config []byte) bool
+
+func (of OwnershipFunc) Owns(pipeline *concourse.Pipeline, config []byte) bool {
+	return of(pipeline, config)
+}
+
+// Converges the pipelines of a team to the pipelines of a project
+type Reconciler struct {
+	// The API to the team pipelines
+	Backend IBackend
+
+	// The team the pipelines are rendered for
+	Team project.TeamName
+
+	// The installation the pipelines are rendered for
+	Installation project.InstallationName
+
+	// Which of the live pipelines the reconciler is allowed to update and remove. A live pipeline with the
+	// name of a project pipeline that is not owned is left as it is. Without ownership the pipelines of the
+	// project are updated regardless of the owner and no pipeline is removed.
+	Ownership IOwnership
+
+	// What to do with the owned pipelines that are not in the project
+	Removal RemovalPolicy
+
+	// Only compute the plan, do not change anything
+	DryRun bool
+}
+
+func (r *Reconciler) owns(pipeline *concourse.Pipeline) (bool, error) {
+	if r.Ownership == nil {
+		return false, nil
+	}
+
+	config, _, err := r.Backend.GetPipelineConfig(pipeline.Name)
+	if err != nil {
+		return false, err
+	}
+
+	return r.Ownership.Owns(pipeline, config), nil
+}
+
+func (r *Reconciler) planRendered(rendered *project.RenderedPipeline, live *concourse.Pipeline) (Plan, error) {
+	name := string(rendered.Name)
+
+	if live == nil {
+		return Plan{
+			&Step{
+				Action:   CreateAction,
+				Pipeline: name,
+				Reason:   "not deployed",
+				Config:   rendered.Config,
+			},
+			&Step{
+				Action:   UnpauseAction,
+				Pipeline: name,
+				Reason:   "created pipelines are paused",
+			},
+		}, nil
+	}
+
+	var plan Plan
+
+	config, version, err := r.Backend.GetPipelineConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Ownership != nil && !r.Ownership.Owns(live, config) {
+		logger.Printf("Pipeline %s is not owned, leaving it", name)
+		return nil, nil
+	}
+
+	// Concourse returns the configuration normalized, the hash does not depend on it
+	var hash project.PipelineHash
+	if config != nil {
+		hash, err = project.HashPipelineConfig(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hash != rendered.Hash || live.Archived {
+		reason := "configuration changed"
+		if live.Archived {
+			reason = "archived"
+		}
+
+		plan = append(plan, &Step{
+			Action:   UpdateAction,
+			Pipeline: name,
+			Reason:   reason,
+			Config:   rendered.Config,
+			Version:  version,
+		})
+	}
+
+	if live.Paused || live.Archived {
+		plan = append(plan, &Step{
+			Action:   UnpauseAction,
+			Pipeline: name,
+			Reason:   "paused",
+		})
+	}
+
+	return plan, nil
+}
+
+func (r *Reconciler) planRemoved(live *concourse.Pipeline) (Plan, error) {
+	if r.Removal == KeepRemoved {
+		return nil, nil
+	}
+
+	owned, err := r.owns(live)
+	if err != nil {
+		return nil, err
+	}
+
+	if !owned {
+		logger.Printf("Pipeline %s is not owned, leaving it", live.Name)
+		return nil, nil
+	}
+
+	step := &Step{
+		Pipeline: live.Name,
+		Reason:   "not in the project",
+	}
+
+	switch r.Removal {
+	case PauseRemoved:
+		if live.Paused || live.Archived {
+			return nil, nil
+		}
+		step.Action = PauseAction
+	case ArchiveRemoved:
+		if live.Archived {
+			return nil, nil
+		}
+		step.Action = ArchiveAction
+	case DestroyRemoved:
+		step.Action = DestroyAction
+	}
+
+	return Plan{step}, nil
+}
+
+// Computes the steps needed for the team pipelines to converge to the project
+func (r *Reconciler) Plan(prj *project.Project) (Plan, error) {
+	rendered, err := prj.Render(r.Team, r.Installation)
+	if err != nil {
+		return nil, err
+	}
+
+	livePipelines, err := r.Backend.Pipelines()
+	if err != nil {
+		return nil, err
+	}
+
+	live := make(map[string]*concourse.Pipeline, len(livePipelines))
+	for _, pipeline := range livePipelines {
+		live[pipeline.Name] = pipeline
+	}
+
+	sort.Slice(rendered, func(i, j int) bool {
+		return rendered[i].Name < rendered[j].Name
+	})
+
+	var plan Plan
+
+	desired := make(map[string]struct{}, len(rendered))
+	for _, pipeline := range rendered {
+		desired[string(pipeline.Name)] = struct{}{}
+
+		steps, err := r.planRendered(pipeline, live[string(pipeline.Name)])
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, steps...)
+	}
+
+	sort.Slice(livePipelines, func(i, j int) bool {
+		return livePipelines[i].Name < livePipelines[j].Name
+	})
+
+	for _, pipeline := range livePipelines {
+		if _, ok := desired[pipeline.Name]; ok {
+			continue
+		}
+
+		steps, err := r.planRemoved(pipeline)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, steps...)
+	}
+
+	return plan, nil
+}
+
+func (r *Reconciler) apply(step *Step) error {
+	switch step.Action {
+	case CreateAction, UpdateAction:
+		_, err := r.Backend.PutPipelineConfig(step.Pipeline, step.Version, step.Config)
+		return err
+	case UnpauseAction:
+		return r.Backend.UnpausePipeline(step.Pipeline)
+	case PauseAction:
+		return r.Backend.PausePipeline(step.Pipeline)
+	case ArchiveAction:
+		return r.Backend.ArchivePipeline(step.Pipeline)
+	case DestroyAction:
+		return r.Backend.DestroyPipeline(step.Pipeline)
+	}
+	return nil
+}
+
+// Computes the plan and applies it, unless in dry run. Returns the plan.
+func (r *Reconciler) Reconcile(prj *project.Project) (Plan, error) {
+	plan, err := r.Plan(prj)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, step := range plan {
+		if r.DryRun {
+			logger.Printf("Dry run: %s", step)
+			continue
+		}
+
+		logger.Printf("Applying: %s", step)
+		err = r.apply(step)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,263 @@
+package deploy
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/concourse"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBackend struct {
+	pipelines map[string]*concourse.Pipeline
+	configs   map[string][]byte
+	versions  map[string]int
+}
+
+func newTestBackend() *testBackend {
+	return &testBackend{
+		pipelines: make(map[string]*concourse.Pipeline),
+		configs:   make(map[string][]byte),
+		versions:  make(map[string]int),
+	}
+}
+
+func (tb *testBackend) Pipelines() ([]*concourse.Pipeline, error) {
+	var pipelines []*concourse.Pipeline
+	for _, pipeline := range tb.pipelines {
+		copied := *pipeline
+		pipelines = append(pipelines, &copied)
+	}
+	return pipelines, nil
+}
+
+func (tb *testBackend) GetPipelineConfig(name string) ([]byte, string, error) {
+	return tb.configs[name], strconv.Itoa(tb.versions[name]), nil
+}
+
+func (tb *testBackend) PutPipelineConfig(name string, version string, config []byte) (bool, error) {
+	_, exists := tb.pipelines[name]
+	if !exists {
+		tb.pipelines[name] = &concourse.Pipeline{Name: name, Paused: true}
+	} else {
+		tb.pipelines[name].Archived = false
+	}
+	tb.configs[name] = config
+	tb.versions[name]++
+	return !exists, nil
+}
+
+func (tb *testBackend) PausePipeline(name string) error {
+	tb.pipelines[name].Paused = true
+	return nil
+}
+
+func (tb *testBackend) UnpausePipeline(name string) error {
+	tb.pipelines[name].Paused = false
+	return nil
+}
+
+func (tb *testBackend) ArchivePipeline(name string) error {
+	tb.pipelines[name].Archived = true
+	tb.pipelines[name].Paused = true
+	return nil
+}
+
+func (tb *testBackend) DestroyPipeline(name string) error {
+	delete(tb.pipelines, name)
+	delete(tb.configs, name)
+	return nil
+}
+
+func testProject(names ...string) *project.Project {
+	prj := &project.Project{}
+	for _, name := range names {
+		pipeline := project.NewPipeline()
+		pipeline.Name = project.PipelineName(name)
+		pipeline.Jobs = project.Jobs{
+			&project.Job{Name: "job"},
+		}
+		prj.Pipelines = append(prj.Pipelines, pipeline)
+	}
+	return prj
+}
+
+var ownsOld = OwnershipFunc(func(pipeline *concourse.Pipeline, config []byte) bool {
+	return strings.HasPrefix(pipeline.Name, "old")
+})
+
+func TestReconcilerCreatesAndConverges(t *testing.T) {
+	backend := newTestBackend()
+	reconciler := &Reconciler{
+		Backend: backend,
+		Team:    "team",
+	}
+
+	plan, err := reconciler.Reconcile(testProject("a", "b"))
+	require.NoError(t, err)
+	assert.Equal(t, "create a: not deployed\n"+
+		"unpause a: created pipelines are paused\n"+
+		"create b: not deployed\n"+
+		"unpause b: created pipelines are paused", plan.String())
+	assert.False(t, backend.pipelines["a"].Paused)
+
+	plan, err = reconciler.Reconcile(testProject("a", "b"))
+	require.NoError(t, err)
+	assert.Empty(t, plan)
+
+	backend.pipelines["b"].Paused = true
+	backend.configs["a"] = []byte("jobs: []")
+
+	plan, err = reconciler.Reconcile(testProject("a", "b"))
+	require.NoError(t, err)
+	assert.Equal(t, "update a: configuration changed\n"+
+		"unpause b: paused", plan.String())
+	assert.Equal(t, 2, backend.versions["a"])
+}
+
+func TestReconcilerSkipsPipelinesNormalizedByConcourse(t *testing.T) {
+	backend := newTestBackend()
+	backend.PutPipelineConfig("a", "", []byte(`{"groups":null,"resources":[],"resource_types":[],`+
+		`"jobs":[{"name":"job","public":false,"serial":false,"plan":null}]}`))
+	backend.UnpausePipeline("a")
+
+	reconciler := &Reconciler{
+		Backend: backend,
+	}
+
+	plan, err := reconciler.Reconcile(testProject("a"))
+	require.NoError(t, err)
+	assert.Empty(t, plan)
+	assert.Equal(t, 1, backend.versions["a"])
+}
+
+func TestReconcilerUpdatesOnlyOwnedPipelines(t *testing.T) {
+	backend := newTestBackend()
+	backend.PutPipelineConfig("old-a", "", []byte("jobs: []"))
+	backend.PutPipelineConfig("foreign", "", []byte("jobs: []"))
+	backend.UnpausePipeline("old-a")
+
+	reconciler := &Reconciler{
+		Backend:   backend,
+		Ownership: ownsOld,
+	}
+
+	plan, err := reconciler.Reconcile(testProject("old-a", "foreign"))
+	require.NoError(t, err)
+	assert.Equal(t, "update old-a: configuration changed", plan.String())
+	assert.Equal(t, "jobs: []", string(backend.configs["foreign"]))
+	assert.True(t, backend.pipelines["foreign"].Paused)
+}
+
+func TestReconcilerDryRun(t *testing.T) {
+	backend := newTestBackend()
+	reconciler := &Reconciler{
+		Backend: backend,
+		DryRun:  true,
+	}
+
+	plan, err := reconciler.Reconcile(testProject("a"))
+	require.NoError(t, err)
+	assert.Len(t, plan, 2)
+	assert.Empty(t, backend.pipelines)
+}
+
+func TestReconcilerRemovesOnlyOwnedPipelines(t *testing.T) {
+	for _, c := range []struct {
+		removal  RemovalPolicy
+		expected string
+	}{
+		{KeepRemoved, ""},
+		{PauseRemoved, "pause old-a: not in the project"},
+		{ArchiveRemoved, "archive old-a: not in the project"},
+		{DestroyRemoved, "destroy old-a: not in the project"},
+	} {
+		backend := newTestBackend()
+		backend.PutPipelineConfig("old-a", "", []byte("jobs: []"))
+		backend.PutPipelineConfig("foreign", "", []byte("jobs: []"))
+		backend.UnpausePipeline("old-a")
+		backend.UnpausePipeline("foreign")
+
+		reconciler := &Reconciler{
+			Backend:   backend,
+			Ownership: ownsOld,
+			Removal:   c.removal,
+		}
+
+		plan, err := reconciler.Reconcile(&project.Project{})
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, plan.String())
+		assert.Contains(t, backend.pipelines, "foreign")
+		assert.False(t, backend.pipelines["foreign"].Paused)
+	}
+}
+
+func TestReconcilerWithoutOwnershipRemovesNothing(t *testing.T) {
+	backend := newTestBackend()
+	backend.PutPipelineConfig("old-a", "", []byte("jobs: []"))
+
+	reconciler := &Reconciler{
+		Backend: backend,
+		Removal: DestroyRemoved,
+	}
+
+	plan, err := reconciler.Reconcile(&project.Project{})
+	require.NoError(t, err)
+	assert.Empty(t, plan)
+	assert.Contains(t, backend.pipelines, "old-a")
+}
+
+func TestReconcilerMarkerOwnership(t *testing.T) {
+	owner := &project.PipelineOwner{
+		Generator: "target-sdp",
+	}
+
+	backend := newTestBackend()
+	backend.PutPipelineConfig("foo-sdpb", "", []byte("resources:\n"+
+		"- name: concourse-builder-owner\n  type: time\n  source:\n    generator: target-sdp\n"))
+	backend.PutPipelineConfig("baz-sdpb", "", []byte("resource_types:\n"+
+		"- name: concourse-builder-owner\n  type: docker-image\n  source:\n    generator: target-sdp\n"))
+	backend.PutPipelineConfig("foo-sdpb-old", "", []byte("jobs: []"))
+	backend.PutPipelineConfig("bar-sdpb", "", []byte("resources:\n"+
+		"- name: concourse-builder-owner\n  type: time\n  source:\n    generator: other-sdp\n"))
+
+	reconciler := &Reconciler{
+		Backend:   backend,
+		Ownership: &MarkerOwnership{Owner: owner},
+		Removal:   DestroyRemoved,
+	}
+
+	plan, err := reconciler.Reconcile(&project.Project{})
+	require.NoError(t, err)
+	assert.Equal(t, "destroy baz-sdpb: not in the project\n"+
+		"destroy foo-sdpb: not in the project", plan.String())
+	assert.Contains(t, backend.pipelines, "foo-sdpb-old")
+	assert.Contains(t, backend.pipelines, "bar-sdpb")
+}
+
+func TestReconcilerAdoptsUnmarkedPipelines(t *testing.T) {
+	backend := newTestBackend()
+	backend.PutPipelineConfig("foo-sdpb", "", []byte("jobs: []"))
+	backend.PutPipelineConfig("foreign", "", []byte("jobs: []"))
+	backend.PutPipelineConfig("bar-sdpb", "", []byte("resources:\n"+
+		"- name: concourse-builder-owner\n  type: time\n  source:\n    generator: other-sdp\n"))
+
+	reconciler := &Reconciler{
+		Backend: backend,
+		Ownership: &MarkerOwnership{
+			Owner:         &project.PipelineOwner{Generator: "target-sdp"},
+			AdoptUnmarked: regexp.MustCompile(".*-sdpb$"),
+		},
+		Removal: DestroyRemoved,
+	}
+
+	plan, err := reconciler.Reconcile(&project.Project{})
+	require.NoError(t, err)
+	assert.Equal(t, "destroy foo-sdpb: not in the project", plan.String())
+	assert.Contains(t, backend.pipelines, "foreign")
+	assert.Contains(t, backend.pipelines, "bar-sdpb")
+}
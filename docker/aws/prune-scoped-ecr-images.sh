@@ -0,0 +1,102 @@
+#!/usr/bin/env bash
+BUILD_DIR=`pwd`
+
+set -e
+
+CHECK_ARGS=true
+
+if [ -z "$REPOSITORY"  ]
+then
+  echo "Please specify REPOSITORY env variable"
+  echo "It specifies the ECR repository which scoped tags to be pruned"
+  CHECK_ARGS=false
+fi
+
+if [ -z "$TAG_PREFIX"  ]
+then
+  echo "Please specify TAG_PREFIX env variable"
+  echo "It specifies the prefix of the tags of the scope the pipelines belong to"
+  CHECK_ARGS=false
+fi
+
+if [ -z "$PIPELINE_REGEX"  ]
+then
+  echo "Please specify PIPELINE_REGEX env variable"
+  echo "It specifies the regular expression of the pipelines which tags to be pruned"
+  CHECK_ARGS=false
+fi
+
+if [ -z "$PIPELINES"  ]
+then
+  echo "Please specify PIPELINES env variable"
+  echo "It specifies a relative to the current directory path with a file for each live pipeline"
+  CHECK_ARGS=false
+fi
+
+if [ "$CHECK_ARGS" == "false" ]
+then
+    exit 1
+fi
+
+. /bin/aws/configure.sh
+
+LIVE_PIPELINES=$(cd $BUILD_DIR/$PIPELINES && for yml in *; do [[ $yml == *.sha256 ]] || echo ${yml%%.*}; done)
+
+# An empty list means the branches were not obtained, pruning would remove the tags of all pipelines
+if [ -z "$LIVE_PIPELINES" ]
+then
+    echo "No live pipelines in $PIPELINES, refusing to prune"
+    exit 1
+fi
+
+set -x
+
+# the tag of a pipeline is the pipeline name, optionally followed by -<tag>. The pipeline of the tag is
+# the longest of the -separated prefixes of the name matching the regular expression, so the tags of
+# pipeline foo-bar are not taken for tags of pipeline foo. Empty if no prefix matches.
+function tag_pipeline {
+    local NAME=$1
+    local CANDIDATE=""
+    local PIPELINE=""
+    local PART
+
+    IFS='-' read -ra PARTS <<< "$NAME"
+    for PART in "${PARTS[@]}"
+    do
+        CANDIDATE="$CANDIDATE${CANDIDATE:+-}$PART"
+        if [[ $CANDIDATE =~ ^($PIPELINE_REGEX)$ ]]
+        then
+            PIPELINE=$CANDIDATE
+        fi
+    done
+    echo "$PIPELINE"
+}
+
+TAGS=$(aws ecr list-images --repository-name $REPOSITORY --filter tagStatus=TAGGED \
+    --query 'imageIds[].imageTag' --output text)
+
+for TAG in $TAGS
+do
+    if [[ $TAG != "$TAG_PREFIX"* ]]
+    then
+        continue
+    fi
+
+    NAME=${TAG#$TAG_PREFIX}
+    PIPELINE=$(tag_pipeline "$NAME")
+
+    # the tags of other pipelines and of the live pipelines are kept
+    if [ -z "$PIPELINE" ] || echo "$LIVE_PIPELINES" | grep -Fxq "$PIPELINE"
+    then
+        continue
+    fi
+
+    if [ "$DRY_RUN" == "true" ]
+    then
+        echo "Would prune $REPOSITORY:$TAG"
+        continue
+    fi
+
+    echo "Pruning $REPOSITORY:$TAG"
+    aws ecr batch-delete-image --repository-name $REPOSITORY --image-ids imageTag=$TAG
+done
@@ -10,6 +10,12 @@ CURRENT_PIPELINES=$(fly --target trgt pipelines -a | awk '{ print $1 }')
 
 for yml in *
 do
+    # the hashes of the pipelines are not pipelines
+    if [[ $yml == *.sha256 ]]
+    then
+        continue
+    fi
+
     name=$(echo $yml | cut -f 1 -d '.')
 
     if echo $CURRENT_PIPELINES | grep -w $name > /dev/null
@@ -0,0 +1,23 @@
+#!/usr/bin/env bash
+
+# Prints the hash of the pipeline configuration (yaml converted to json or json) read from the standard input.
+# Concourse returns the configurations normalized, the hash is of the same normalized form
+# project.HashPipelineConfig hashes, so the hash of the deployed pipeline matches the hash of the rendered one.
+pipeline_hash() {
+    jq -S -c '
+        def empty_value: . == null or . == false or . == 0 or . == "" or . == {} or . == [];
+        def canonical:
+            if type == "object" then
+                map_values(canonical)
+                | with_entries(select(.value | empty_value | not))
+                | if has("aggregate") then .in_parallel = .aggregate | del(.aggregate) else . end
+                | if (.in_parallel | type) == "array" then .in_parallel = {steps: .in_parallel} else . end
+            elif type == "array" then
+                map(canonical)
+            elif type == "boolean" or type == "number" then
+                if empty_value then null else tostring end
+            else
+                .
+            end;
+        canonical' | sha256sum | awk '{ print $1 }'
+}
@@ -5,31 +5,50 @@ set -ex
 
 . /bin/fly/authenticate.sh
 
-if [ -z "$PIPELINE_REGEX"  ]
+if [ -z "$PIPELINE_GENERATOR"  ]
 then
-  echo "Please specify PIPELINE_REGEX env variable"
-  echo "It specifies a regexp pattern which pipelines to be considered for removal"
+  echo "Please specify PIPELINE_GENERATOR env variable"
+  echo "It specifies the generator which owned pipelines to be considered for removal"
   exit 1
 fi
 
 cd $PIPELINES
 
 CURRENT_PIPELINES=$(fly --target trgt pipelines | awk '{ print $1 }' | sort)
-PIPELINE_FILES=$(for yml in *; do echo $(echo $yml | cut -f 1 -d '.'); done)
+PIPELINE_FILES=$(for yml in *; do [[ $yml == *.sha256 ]] || echo $(echo $yml | cut -f 1 -d '.'); done)
 
 for pipeline in $CURRENT_PIPELINES
 do
-    # check if the pipeline matches the regular expression
-    if [[ ! $pipeline =~ $PIPELINE_REGEX ]]
+    # check if the pipeline matches the optional regular expression
+    if [ ! -z "$PIPELINE_REGEX" ] && [[ ! $pipeline =~ $PIPELINE_REGEX ]]
     then
         continue
     fi
 
-    # check if the pipeline is in the list of generated pipelines
-    if echo "$PIPELINE_FILES" | grep "$pipeline"
+    # check if the pipeline is exactly one of the generated pipelines
+    if echo "$PIPELINE_FILES" | grep -Fxq "$pipeline"
     then
         continue
     fi
 
+    # check if the pipeline is owned by the generator, the pipelines marked before the marker became
+    # a resource carry it as a resource type
+    OWNER=$(fly -t trgt get-pipeline --json --pipeline=$pipeline |\
+        jq -r '[(.resources[]?, .resource_types[]?) | select(.name == "concourse-builder-owner") | .source.generator]
+            | first // empty')
+
+    # the pipelines deployed before the pipelines were marked have no owner, they are adopted on request
+    if [ -z "$OWNER" ] && [ "$ADOPT_UNMARKED" == "true" ]
+    then
+        echo "'$pipeline' pipeline has no owner, adopting it"
+        OWNER=$PIPELINE_GENERATOR
+    fi
+
+    if [ ! "$OWNER" == "$PIPELINE_GENERATOR" ]
+    then
+        echo "'$pipeline' pipeline is not owned by '$PIPELINE_GENERATOR', skipping"
+        continue
+    fi
+
     fly -t trgt destroy-pipeline --non-interactive --pipeline=$pipeline
 done
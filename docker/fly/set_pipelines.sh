@@ -3,11 +3,30 @@
 set -ex
 
 . /bin/fly/authenticate.sh
+. /bin/fly/pipeline_hash.sh
 
 cd $PIPELINES
 
 for yml in *
 do
+    # the hashes of the pipelines are not pipelines
+    if [[ $yml == *.sha256 ]]
+    then
+        continue
+    fi
+
     name=$(echo $yml | cut -f 1 -d '.')
+
+    # skip the pipeline if the deployed configuration has the same hash
+    if [ -e "$yml.sha256" ]
+    then
+        DEPLOYED_HASH=$(fly -t trgt get-pipeline --json --pipeline=$name | pipeline_hash)
+        if [ "$DEPLOYED_HASH" == "$(cat $yml.sha256)" ]
+        then
+            echo "'$name' pipeline is not changed, skipping"
+            continue
+        fi
+    fi
+
     fly -t trgt set-pipeline --non-interactive --pipeline=$name --config=$yml
 done
\ No newline at end of file
@@ -1,6 +1,8 @@
 package library
 
 import (
+	"fmt"
+
 	"github.com/concourse-friends/concourse-builder/library/image"
 	"github.com/concourse-friends/concourse-builder/library/primitive"
 	"github.com/concourse-friends/concourse-builder/model"
@@ -22,12 +24,17 @@ type BuildImageArgs struct {
 	From               *project.Resource
 	Name               string
 	DockerFileResource project.IValue
-	DockerFileSteps    string
+	Dockerfile         *image.Dockerfile
 	Image              *project.Resource
 	BuildArgs          map[string]interface{}
 	PreprepareSteps    project.ISteps
 	SourceDirs         []interface{}
-	Eval               string
+
+	// The platforms the image is built for, the ones of the image registry if nil
+	Platforms image.Platforms
+
+	// The tags pushed in addition to the scoped tag, optional
+	Tags *image.TagPolicy
 }
 
 func taskPrepare(args *BuildImageArgs) *project.TaskStep {
@@ -60,11 +67,12 @@ set -ex
 
 CHECK_ARGS=true
 
-if [ -z "$DOCKERFILE_DIR" -a -z "$DOCKERFILE_STEPS" ]
+if [ -z "$DOCKERFILE_DIR" -a -z "$DOCKERFILE_STEPS" -a -z "$DOCKERFILE_STAGES" ]
 then
-	echo "Please specify DOCKERFILE_DIR or DOCKERFILE_STEPS env variable"
+	echo "Please specify DOCKERFILE_DIR, DOCKERFILE_STEPS or DOCKERFILE_STAGES env variable"
 	echo "DOCKERFILE_DIR specifies the directory where the dockerfile steps are"
 	echo "DOCKERFILE_STEPS is a base64 gzip string of the dockerfile steps"
+	echo "DOCKERFILE_STAGES is a base64 gzip string of the dockerfile stages before the FROM image"
 	CHECK_ARGS=false
 fi
 
@@ -96,23 +104,35 @@ cd  prepared
 
 REPOSITORY=$(cat $ROOT/$FROM_IMAGE/repository)
 TAG=$(cat $ROOT/$FROM_IMAGE/tag)
-echo FROM $REPOSITORY:$TAG > Dockerfile
-echo >> Dockerfile
+> Dockerfile
+if [ ! -z "$FROM_IMAGE_ARG" ]
+then
+    echo ARG $FROM_IMAGE_ARG >> Dockerfile
+    echo >> Dockerfile
+fi
 
-if [ ! -z "$EVAL" ]
+if [ ! -z "$DOCKERFILE_STAGES" ]
 then
-    eval "$EVAL" >> Dockerfile
+    echo "$DOCKERFILE_STAGES" | tr -d '\n' | base64 --decode | gzip -cfd >> Dockerfile
 fi
 
-echo >> Dockerfile
-if [ ! -z "$DOCKERFILE_DIR" -a -e $ROOT/$DOCKERFILE_DIR/steps ]
+if [ ! -z "$FROM_IMAGE_ARG" ]
 then
-    cat $ROOT/$DOCKERFILE_DIR/steps >> Dockerfile
+    echo FROM \${$FROM_IMAGE_ARG} >> Dockerfile
+else
+    echo FROM $REPOSITORY:$TAG >> Dockerfile
 fi
+echo >> Dockerfile
 
 if [ ! -z "$DOCKERFILE_STEPS" ]
 then
     echo "$DOCKERFILE_STEPS" | tr -d '\n' | base64 --decode | gzip -cfd >> Dockerfile
+    echo >> Dockerfile
+fi
+
+if [ ! -z "$DOCKERFILE_DIR" -a -e $ROOT/$DOCKERFILE_DIR/steps ]
+then
+    cat $ROOT/$DOCKERFILE_DIR/steps >> Dockerfile
 fi
 `
 
@@ -126,23 +146,30 @@ fi
 		taskPrepare.Environment["DOCKERFILE_DIR"] = args.DockerFileResource
 	}
 
-	if args.DockerFileSteps != "" {
-		taskPrepare.Environment["DOCKERFILE_STEPS"] = GZipBase64Lines(args.DockerFileSteps, "\n")
+	if args.Dockerfile != nil {
+		stages, steps := args.Dockerfile.MustRender()
+
+		if stages != "" {
+			taskPrepare.Environment["DOCKERFILE_STAGES"] = GZipBase64Lines(stages, "\n")
+		}
+
+		if steps != "" {
+			taskPrepare.Environment["DOCKERFILE_STEPS"] = GZipBase64Lines(steps, "\n")
+		}
 	}
 
 	if len(args.SourceDirs) > 0 {
 		taskPrepare.Environment["SOURCE_DIRS"] = primitive.Array(args.SourceDirs)
 	}
 
-	if args.Eval != "" {
-		taskPrepare.Environment["EVAL"] = args.Eval
-	}
-
 	return taskPrepare
 }
 
-func BuildImage(args *BuildImageArgs) *project.Job {
-	taskPrepare := taskPrepare(args)
+// Builds the image with the docker-image put
+func dockerBuildSteps(args *BuildImageArgs, taskPrepare *project.TaskStep, tags image.IBuild) (project.ISteps, error) {
+	if len(imagePlatforms(args)) > 0 {
+		return nil, fmt.Errorf("The image %s is built for platforms, it needs the OCI backend", args.Image.Name)
+	}
 
 	imageSource := args.From.Source.(*image.Source)
 	public := imageSource.Registry.Public()
@@ -165,22 +192,208 @@ func BuildImage(args *BuildImageArgs) *project.Job {
 			Build: &primitive.Location{
 				RelativePath: preparedDir.Path(),
 			},
-			BuildArgs: args.BuildArgs,
+			BuildArgs:      args.BuildArgs,
+			AdditionalTags: tags,
 		},
 		GetParams: &resource.ImageGetParams{
 			SkipDownload: true,
 		},
 	}
 
+	return project.ISteps{putImage}, nil
+}
+
+// The directory oci-build-task writes the image to
+const ociBuildTaskOutput = "image"
+
+// The oci-build-task task that builds the image for the platform, for the platform of the worker if nil
+func ociBuildTask(args *BuildImageArgs, preparedDir project.IOutput, platform *image.Platform,
+	output *project.TaskOutput) *project.TaskStep {
+
+	name := "build"
+	if platform != nil {
+		name = "build-" + platform.Architecture
+	}
+
+	taskBuild := &project.TaskStep{
+		Platform:   model.LinuxPlatform,
+		Name:       project.TaskName(name),
+		Image:      args.ResourceRegistry.JobResource(image.OciBuildTask, true, nil),
+		Privileged: true,
+		Run: &primitive.Location{
+			RelativePath: "build",
+		},
+		Environment: map[string]interface{}{
+			"CONTEXT": &primitive.Location{
+				Volume: preparedDir,
+			},
+		},
+		Outputs: []project.IOutput{
+			output,
+		},
+	}
+
+	if platform != nil {
+		taskBuild.Environment["IMAGE_PLATFORM"] = platform.String()
+		taskBuild.Tags = platform.WorkerTags
+	}
+
+	if !args.From.Source.(*image.Source).Registry.Public() {
+		from := args.From
+		if platform != nil {
+			from = platformImage(args.From, platform)
+		}
+
+		fromImageResource := args.ResourceRegistry.JobResource(from, true, &resource.RegistryImageGetParams{
+			Format: "oci",
+		})
+
+		taskBuild.Environment["IMAGE_ARG_base_image"] = &primitive.Location{
+			Volume:       fromImageResource,
+			RelativePath: "image.tar",
+		}
+	}
+
+	for k, v := range args.BuildArgs {
+		taskBuild.Environment["BUILD_ARG_"+k] = v
+	}
+
+	return taskBuild
+}
+
+// The image pinned to the platform
+func platformImage(from *project.Resource, platform *image.Platform) *project.Resource {
+	source := *from.Source.(*image.Source)
+	source.Platform = platform
+
+	pinned := &project.Resource{
+		Name:          project.ConvertToResourceName(string(from.Name) + "-" + platform.Architecture),
+		Type:          from.Type,
+		Source:        &source,
+		Scope:         from.Scope,
+		Naming:        from.Naming,
+		CheckInterval: from.CheckInterval,
+	}
+	pinned.NeedJobs(from.NeededJobs()...)
+
+	return pinned
+}
+
+// Builds the image with oci-build-task tasks and pushes it with the registry-image put.
+// Images of several platforms are built by a task per platform and pushed as a single multi-platform image.
+func ociBuildSteps(args *BuildImageArgs, taskPrepare *project.TaskStep, tags image.IBuild) (project.ISteps, error) {
+	imageSource := args.From.Source.(*image.Source)
+	public := imageSource.Registry.Public()
+
+	if !public && imageSource.Registry.Backend != image.OciBackend {
+		return nil, fmt.Errorf("The image %s is built with the OCI backend, its From image %s must be public "+
+			"or built with the OCI backend too", args.Image.Name, args.From.Name)
+	}
+
+	if !public {
+		taskPrepare.Environment["FROM_IMAGE_ARG"] = "base_image"
+	}
+
+	preparedDir := taskPrepare.Outputs[0]
+	platforms := imagePlatforms(args)
+
+	imageDir := &project.TaskOutput{
+		Directory: ociBuildTaskOutput,
+	}
+
+	var steps project.ISteps
+	if len(platforms) <= 1 {
+		var platform *image.Platform
+		if len(platforms) == 1 {
+			platform = platforms[0]
+		}
+
+		steps = append(steps, ociBuildTask(args, preparedDir, platform, imageDir))
+	} else {
+		builds := &project.AggregateStep{}
+		var images primitive.Array
+
+		for _, platform := range platforms {
+			// oci-build-task always writes to image, the output is renamed for the manifest task
+			platformDir := &project.TaskOutput{
+				Directory: "image-" + platform.Architecture,
+				WrittenTo: ociBuildTaskOutput,
+			}
+
+			taskBuild := ociBuildTask(args, preparedDir, platform, platformDir)
+			taskBuild.Environment["OUTPUT_OCI"] = "true"
+			builds.Steps = append(builds.Steps, taskBuild)
+
+			images = append(images, &primitive.Location{
+				Volume:       platformDir,
+				RelativePath: "image",
+			})
+		}
+
+		steps = append(steps, builds, taskManifest(args, platforms, images, imageDir))
+	}
+
+	putImage := &project.PutStep{
+		Resource: args.Image,
+		Params: &image.OciPutParams{
+			Image: &primitive.Location{
+				Volume:       imageDir,
+				RelativePath: "image.tar",
+			},
+			AdditionalTags: tags,
+		},
+		GetParams: &resource.RegistryImageGetParams{
+			SkipDownload: true,
+		},
+	}
+
+	return append(steps, putImage), nil
+}
+
+// The platforms of the image, the ones of its registry if the args do not specify them
+func imagePlatforms(args *BuildImageArgs) image.Platforms {
+	if args.Platforms != nil {
+		return args.Platforms
+	}
+	return args.Image.Source.(*image.Source).Registry.Platforms
+}
+
+func BuildImage(args *BuildImageArgs) (*project.Job, error) {
+	taskPrepare := taskPrepare(args)
+
 	imageJob := &project.Job{
 		Name: project.JobName(args.Name + "-image"),
 		Groups: project.JobGroups{
 			ImagesGroup,
 		},
-		Steps: args.PreprepareSteps,
+		Steps: append(project.ISteps(nil), args.PreprepareSteps...),
 	}
 
-	imageJob.Steps = append(imageJob.Steps, taskPrepare, putImage)
+	imageJob.Steps = append(imageJob.Steps, taskPrepare)
 
-	return imageJob
+	tags, err := additionalTags(imageJob, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var buildSteps project.ISteps
+	if args.Image.Source.(*image.Source).Registry.Backend == image.OciBackend {
+		buildSteps, err = ociBuildSteps(args, taskPrepare, tags)
+	} else {
+		buildSteps, err = dockerBuildSteps(args, taskPrepare, tags)
+	}
+	if err != nil {
+		return nil, err
+	}
+	imageJob.Steps = append(imageJob.Steps, buildSteps...)
+
+	return imageJob, nil
+}
+
+func MustBuildImage(args *BuildImageArgs) *project.Job {
+	job, err := BuildImage(args)
+	if err != nil {
+		panic(err.Error())
+	}
+	return job
 }
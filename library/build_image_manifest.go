@@ -0,0 +1,86 @@
+package library
+
+import (
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+// Merges the OCI layouts of the platform images into an OCI image tarball with an image index
+const manifestScript = `#!/usr/bin/env bash
+
+set -ex
+
+PLATFORMS=($PLATFORMS)
+IMAGES=($IMAGES)
+
+if [ ${#PLATFORMS[@]} -ne ${#IMAGES[@]} ]
+then
+	echo "PLATFORMS and IMAGES must have the same number of items"
+	exit 1
+fi
+
+if ! command -v jq > /dev/null
+then
+    if command -v apt-get > /dev/null
+    then
+        apt-get update && apt-get install -y jq
+    else
+        apk add --no-cache jq
+    fi
+fi
+
+mkdir -p layout/blobs
+
+MANIFESTS='[]'
+for i in ${!PLATFORMS[@]}
+do
+    PLATFORM=${PLATFORMS[$i]}
+    IMAGE=${IMAGES[$i]}
+
+    cp -R $IMAGE/blobs/. layout/blobs/
+
+    # The manifests of the platform image annotated with the platform
+    MANIFESTS=$(jq -c --argjson manifests "$MANIFESTS" --arg platform "$PLATFORM" '
+        ($platform | split("/")) as [$os, $architecture, $variant] |
+        ({os: $os, architecture: $architecture} + (if $variant then {variant: $variant} else {} end)) as $annotation |
+        $manifests + [.manifests[] | .platform = $annotation]' $IMAGE/index.json)
+done
+
+jq -n -c '{imageLayoutVersion: "1.0.0"}' > layout/oci-layout
+jq -n -c --argjson manifests "$MANIFESTS" \
+    '{schemaVersion: 2, mediaType: "application/vnd.oci.image.index.v1+json", manifests: $manifests}' \
+    > layout/index.json
+
+mkdir -p $OUTPUT
+tar -cf $OUTPUT/image.tar -C layout .
+`
+
+// The task that assembles the images of the platforms into a single multi-platform image
+func taskManifest(args *BuildImageArgs, platforms image.Platforms, images primitive.Array,
+	output *project.TaskOutput) *project.TaskStep {
+
+	var platformNames primitive.Array
+	for _, platform := range platforms {
+		platformNames = append(platformNames, platform.String())
+	}
+
+	task := &project.TaskStep{
+		Platform: model.LinuxPlatform,
+		Name:     "manifest",
+		Image:    args.ResourceRegistry.JobResource(args.PrepareImage, true, nil),
+		Environment: map[string]interface{}{
+			"PLATFORMS": platformNames,
+			"IMAGES":    images,
+			"OUTPUT":    output.Path(),
+		},
+		Outputs: []project.IOutput{
+			output,
+		},
+	}
+
+	task.Run, task.Arguments = EncodeScript(manifestScript)
+
+	return task
+}
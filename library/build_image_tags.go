@@ -0,0 +1,138 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+// Writes the tags of the policy into a single file, one per line
+const tagsScript = `#!/usr/bin/env bash
+
+set -e
+
+mkdir -p $OUTPUT
+touch $OUTPUT/all
+
+for TAG in $TAGS
+do
+    echo $TAG >> $OUTPUT/all
+done
+
+for FILE in $TAG_FILES
+do
+    cat $FILE >> $OUTPUT/all
+    echo >> $OUTPUT/all
+done
+
+if [ ! -z "$TIMESTAMP" ]
+then
+    date -u +%Y%m%d%H%M%S >> $OUTPUT/all
+fi
+
+tr -s ' \t\n' '\n' < $OUTPUT/all | sed '/^$/d' | sort -u > $OUTPUT/tags
+rm $OUTPUT/all
+
+if [ ! -z "$TAG_PREFIX" ]
+then
+    sed -i "s/^/$TAG_PREFIX-/" $OUTPUT/tags
+fi
+
+if grep -Ev '^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$' $OUTPUT/tags
+then
+    echo "The tags above are not valid image tags"
+    exit 1
+fi
+
+cat $OUTPUT/tags
+`
+
+// The task that writes the tags of the policy into the tags file of the output
+func taskTags(args *BuildImageArgs, output *project.TaskOutput) (*project.TaskStep, error) {
+	policy := args.Tags
+
+	err := policy.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("The tags of image %s: %s", args.Image.Name, err.Error())
+	}
+
+	task := &project.TaskStep{
+		Platform: model.LinuxPlatform,
+		Name:     "tags",
+		Image:    args.ResourceRegistry.JobResource(args.PrepareImage, true, nil),
+		Environment: map[string]interface{}{
+			"OUTPUT": output.Path(),
+		},
+		Outputs: []project.IOutput{
+			output,
+		},
+	}
+
+	if len(policy.Tags) > 0 {
+		var tags primitive.Array
+		for _, tag := range policy.Tags {
+			tags = append(tags, string(tag))
+		}
+		task.Environment["TAGS"] = tags
+	}
+
+	var files primitive.Array
+	for _, file := range policy.Files {
+		files = append(files, file)
+	}
+
+	ref := ".git/ref"
+	if policy.ShortGitRefs {
+		ref = ".git/short_ref"
+	}
+
+	for _, git := range policy.GitRefs {
+		files = append(files, &primitive.Location{
+			Volume:       git,
+			RelativePath: ref,
+		})
+	}
+
+	if len(files) > 0 {
+		task.Environment["TAG_FILES"] = files
+	}
+
+	if policy.Timestamp {
+		task.Environment["TIMESTAMP"] = "true"
+	}
+
+	if !policy.Unscoped {
+		task.Environment["TAG_PREFIX"] = &image.ScopedTag{
+			Image: args.Image,
+		}
+	}
+
+	task.Run, task.Arguments = EncodeScript(tagsScript)
+
+	return task, nil
+}
+
+// The file with the additional tags, nil if the image has no tag policy
+func additionalTags(imageJob *project.Job, args *BuildImageArgs) (image.IBuild, error) {
+	if args.Tags == nil {
+		return nil, nil
+	}
+
+	tagsDir := &project.TaskOutput{
+		Directory: "tags",
+	}
+
+	task, err := taskTags(args, tagsDir)
+	if err != nil {
+		return nil, err
+	}
+	imageJob.Steps = append(imageJob.Steps, task)
+
+	return &primitive.Location{
+		Volume:       tagsDir,
+		RelativePath: "tags",
+	}, nil
+}
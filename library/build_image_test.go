@@ -0,0 +1,326 @@
+package library
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func buildImagePipeline(t *testing.T, backend image.Backend) string {
+	registry := &image.Registry{
+		Domain:             "123.dkr.ecr.eu-west-1.amazonaws.com",
+		AwsAccessKeyId:     "key",
+		AwsSecretAccessKey: "secret",
+		Backend:            backend,
+	}
+
+	pipeline := project.NewPipeline()
+	pipeline.Name = "images"
+
+	base := &project.Resource{
+		Name: "base-image",
+		Type: registry.ResourceType(),
+		Source: &image.Source{
+			Registry:   registry,
+			Repository: "base",
+		},
+	}
+
+	built := &project.Resource{
+		Name: "built-image",
+		Type: registry.ResourceType(),
+		Source: &image.Source{
+			Registry:   registry,
+			Repository: "built",
+		},
+	}
+	pipeline.ResourceRegistry.MustRegister(built)
+
+	dockerfile := &image.Dockerfile{}
+	dockerfile.Final().User("root")
+
+	pipeline.Jobs = project.Jobs{
+		MustBuildImage(&BuildImageArgs{
+			ResourceRegistry: pipeline.ResourceRegistry,
+			PrepareImage:     image.Ubuntu,
+			From:             base,
+			Name:             "built",
+			Dockerfile:       dockerfile,
+			Image:            built,
+			BuildArgs: map[string]interface{}{
+				"VERSION": "1",
+			},
+		}),
+	}
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	return yml.String()
+}
+
+func TestBuildImageDockerBackend(t *testing.T) {
+	rendered := buildImagePipeline(t, image.DockerImageBackend)
+
+	assert.Contains(t, rendered, "type: docker-image")
+	assert.NotContains(t, rendered, "registry-image")
+	assert.Contains(t, rendered, "load_base: base-image")
+	assert.Contains(t, rendered, "build: prepared")
+	assert.NotContains(t, rendered, "oci-build-task")
+}
+
+func TestBuildImageOciBackend(t *testing.T) {
+	rendered := buildImagePipeline(t, image.OciBackend)
+
+	assert.Contains(t, rendered, "type: registry-image")
+	assert.Contains(t, rendered, "aws_region: eu-west-1")
+	assert.Contains(t, rendered, "repository: concourse/oci-build-task")
+	assert.Contains(t, rendered, "privileged: true")
+	assert.Contains(t, rendered, "format: oci")
+	assert.Contains(t, rendered, "FROM_IMAGE_ARG: base_image")
+	assert.Contains(t, rendered, "IMAGE_ARG_base_image: base-image/image.tar")
+	assert.Contains(t, rendered, "BUILD_ARG_VERSION: \"1\"")
+	assert.Contains(t, rendered, "image: image/image.tar")
+	assert.NotContains(t, rendered, "load_base")
+}
+
+func TestBuildImageOciBackendNeedsOciBase(t *testing.T) {
+	base := &project.Resource{
+		Name: "base-image",
+		Type: "docker-image",
+		Source: &image.Source{
+			Registry: &image.Registry{
+				AwsAccessKeyId:     "key",
+				AwsSecretAccessKey: "secret",
+			},
+			Repository: "base",
+		},
+	}
+
+	built := &project.Resource{
+		Name: "built-image",
+		Type: "registry-image",
+		Source: &image.Source{
+			Registry: &image.Registry{
+				Backend: image.OciBackend,
+			},
+			Repository: "built",
+		},
+	}
+
+	_, err := BuildImage(&BuildImageArgs{
+		ResourceRegistry: project.NewResourceRegistry(),
+		PrepareImage:     image.Ubuntu,
+		From:             base,
+		Name:             "built",
+		Image:            built,
+	})
+	assert.Error(t, err)
+}
+
+func TestBuildImagePlatforms(t *testing.T) {
+	registry := &image.Registry{
+		Domain:             "123.dkr.ecr.eu-west-1.amazonaws.com",
+		AwsAccessKeyId:     "key",
+		AwsSecretAccessKey: "secret",
+		Backend:            image.OciBackend,
+		Platforms: image.Platforms{
+			image.LinuxAmd64,
+			&image.Platform{
+				OS:           "linux",
+				Architecture: "arm64",
+				WorkerTags:   []string{"arm"},
+			},
+		},
+	}
+
+	base := &project.Resource{
+		Name: "base-image",
+		Type: registry.ResourceType(),
+		Source: &image.Source{
+			Registry:   registry,
+			Repository: "base",
+		},
+	}
+
+	built := &project.Resource{
+		Name: "built-image",
+		Type: registry.ResourceType(),
+		Source: &image.Source{
+			Registry:   registry,
+			Repository: "built",
+		},
+	}
+
+	pipeline := project.NewPipeline()
+	pipeline.Name = "images"
+	pipeline.ResourceRegistry.MustRegister(built)
+
+	job := MustBuildImage(&BuildImageArgs{
+		ResourceRegistry: pipeline.ResourceRegistry,
+		PrepareImage:     image.Ubuntu,
+		From:             base,
+		Name:             "built",
+		Image:            built,
+	})
+	pipeline.Jobs = project.Jobs{job}
+
+	require.Len(t, job.Steps, 4)
+	builds, ok := job.Steps[1].(*project.AggregateStep)
+	require.True(t, ok)
+	require.Len(t, builds.Steps, 2)
+
+	arm := builds.Steps[1].(*project.TaskStep)
+	assert.Equal(t, project.TaskName("build-arm64"), arm.Name)
+	assert.Equal(t, []string{"arm"}, arm.Tags)
+	assert.Equal(t, "linux/arm64", arm.Environment["IMAGE_PLATFORM"])
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	rendered := yml.String()
+
+	assert.Contains(t, rendered, "- name: base-image-amd64")
+	assert.Contains(t, rendered, "- name: base-image-arm64")
+	assert.Contains(t, rendered, "architecture: arm64")
+	assert.Contains(t, rendered, "IMAGE_ARG_base_image: base-image-arm64/image.tar")
+	assert.Contains(t, rendered, "PLATFORMS: linux/amd64 linux/arm64")
+	assert.Contains(t, rendered, "IMAGES: image-amd64/image image-arm64/image")
+	assert.Contains(t, rendered, "task: manifest")
+	assert.Contains(t, rendered, "image: image/image.tar")
+
+	// The outputs are where oci-build-task writes the image, the manifest task gets them by their names
+	type taskConfig struct {
+		Inputs  []map[string]string
+		Outputs []map[string]string
+		Params  map[string]string
+	}
+	plan := struct {
+		Jobs []struct {
+			Plan []struct {
+				Aggregate []struct {
+					Task   string
+					Config taskConfig
+				}
+				Task   string
+				Config taskConfig
+			}
+		}
+	}{}
+	require.NoError(t, yaml.Unmarshal(yml.Bytes(), &plan))
+	require.Len(t, plan.Jobs, 1)
+
+	inputs := map[string]map[string]string{}
+	for _, step := range plan.Jobs[0].Plan {
+		for _, build := range step.Aggregate {
+			if build.Task == "" {
+				continue
+			}
+			require.Len(t, build.Config.Outputs, 1, build.Task)
+			output := build.Config.Outputs[0]
+			assert.Equal(t, "image", output["path"], build.Task)
+			assert.Equal(t, "true", build.Config.Params["OUTPUT_OCI"], build.Task)
+			inputs[output["name"]] = output
+		}
+
+		if step.Task != "manifest" {
+			continue
+		}
+
+		for _, image := range strings.Fields(step.Config.Params["IMAGES"]) {
+			name := strings.SplitN(image, "/", 2)[0]
+			require.Contains(t, inputs, name)
+			assert.Contains(t, step.Config.Inputs, map[string]string{"name": name})
+		}
+		assert.Equal(t, []map[string]string{{"name": "image", "path": "image"}}, step.Config.Outputs)
+	}
+	assert.Len(t, inputs, 2)
+
+	// Only the OCI backend builds for platforms
+	registry.Backend = image.DockerImageBackend
+	_, err := BuildImage(&BuildImageArgs{
+		ResourceRegistry: pipeline.ResourceRegistry,
+		PrepareImage:     image.Ubuntu,
+		From:             base,
+		Name:             "built",
+		Image:            built,
+	})
+	assert.Error(t, err)
+}
+
+func TestBuildImageTags(t *testing.T) {
+	pipeline := project.NewPipeline()
+	pipeline.Name = "images"
+
+	git := &project.Resource{
+		Name: "git",
+		Type: "git",
+		Source: &GitSource{
+			Repo:   &primitive.GitRepo{URI: "git@github.com:org/repo.git"},
+			Branch: &primitive.GitBranch{Name: "master"},
+		},
+	}
+
+	built := &project.Resource{
+		Name:  "built-image",
+		Type:  image.DockerHub.ResourceType(),
+		Scope: project.UniverseScope,
+		Source: &image.Source{
+			Registry:   image.DockerHub,
+			Repository: "built",
+		},
+	}
+	pipeline.ResourceRegistry.MustRegister(built)
+
+	dockerfile := &image.Dockerfile{}
+	dockerfile.Final().User("root")
+
+	args := &BuildImageArgs{
+		ResourceRegistry: pipeline.ResourceRegistry,
+		PrepareImage:     image.Ubuntu,
+		From:             image.Alpine,
+		Name:             "built",
+		Dockerfile:       dockerfile,
+		Image:            built,
+		Tags: &image.TagPolicy{
+			Tags:         []image.Tag{"latest"},
+			GitRefs:      project.JobResources{pipeline.ResourceRegistry.JobResource(git, false, nil)},
+			ShortGitRefs: true,
+			Timestamp:    true,
+		},
+	}
+	pipeline.Jobs = project.Jobs{MustBuildImage(args)}
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	rendered := yml.String()
+
+	assert.Contains(t, rendered, "task: tags")
+	assert.Contains(t, rendered, "TAGS: latest")
+	assert.Contains(t, rendered, "TAG_FILES: git/.git/short_ref")
+	assert.Contains(t, rendered, "TIMESTAMP: \"true\"")
+	assert.Contains(t, rendered, "additional_tags: tags/tags")
+	assert.Contains(t, rendered, "tag: installation-team-images")
+
+	// The extra tags are prefixed with the scoped tag
+	assert.Contains(t, rendered, "TAG_PREFIX: installation-team-images\n")
+
+	args.Tags.Unscoped = true
+	pipeline.Jobs = project.Jobs{MustBuildImage(args)}
+	yml.Reset()
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	assert.NotContains(t, yml.String(), "TAG_PREFIX")
+
+	args.Tags = &image.TagPolicy{Tags: []image.Tag{"-latest"}}
+	_, err := BuildImage(args)
+	assert.EqualError(t, err, "The tags of image built-image: Invalid image tag \"-latest\"")
+
+	args.Tags = &image.TagPolicy{}
+	_, err = BuildImage(args)
+	assert.Error(t, err)
+}
@@ -5,7 +5,6 @@ import (
 	"github.com/concourse-friends/concourse-builder/library/primitive"
 	"github.com/concourse-friends/concourse-builder/model"
 	"github.com/concourse-friends/concourse-builder/project"
-	"github.com/concourse-friends/concourse-builder/resource"
 )
 
 type DummyResourceImageJobArgs struct {
@@ -23,7 +22,7 @@ func DummyResourceJob(args *DummyResourceImageJobArgs) *ResourceImageSource {
 
 	imageResource = &project.Resource{
 		Name:  resourceName,
-		Type:  resource.ImageResourceType.Name,
+		Type:  args.ImageRegistry.ResourceType(),
 		Scope: project.TeamScope,
 		Source: &image.Source{
 			Registry:   args.ImageRegistry,
@@ -38,7 +37,7 @@ func DummyResourceJob(args *DummyResourceImageJobArgs) *ResourceImageSource {
 		RelativePath: "docker/dummy_resource",
 	}
 
-	job := BuildImage(
+	job := MustBuildImage(
 		&BuildImageArgs{
 			ResourceRegistry:   args.ResourceRegistry,
 			PrepareImage:       image.Ubuntu,
@@ -59,7 +58,7 @@ func DummyResourceType(args *DummyResourceImageJobArgs) *project.ResourceType {
 
 	dummyResourceType := &project.ResourceType{
 		Name:   "dummy",
-		Type:   model.DockerImageType,
+		Type:   model.ResourceTypeTypeName(args.ImageRegistry.ResourceType()),
 		Source: source,
 	}
 
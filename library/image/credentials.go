@@ -0,0 +1,100 @@
+package image
+
+import (
+	"fmt"
+
+	"github.com/concourse-friends/concourse-builder/resource"
+)
+
+// The credentials a registry is accessed with
+type ICredentials interface {
+	Validate() error
+
+	// Sets the credentials in the source of a docker-image resource
+	ImageSource(source *resource.ImageSource, registry *Registry)
+
+	// Sets the credentials in the source of a registry-image resource
+	RegistryImageSource(source *resource.RegistryImageSource, registry *Registry)
+}
+
+// Username and password credentials, used by Docker Hub, ACR, Harbor and most other registries
+type UsernamePassword struct {
+	Username string
+	Password string
+}
+
+func (up *UsernamePassword) Validate() error {
+	if up.Username == "" || up.Password == "" {
+		return fmt.Errorf("Username and Password make sense only as pair")
+	}
+	return nil
+}
+
+func (up *UsernamePassword) ImageSource(source *resource.ImageSource, registry *Registry) {
+	source.Username = up.Username
+	source.Password = up.Password
+}
+
+func (up *UsernamePassword) RegistryImageSource(source *resource.RegistryImageSource, registry *Registry) {
+	source.Username = up.Username
+	source.Password = up.Password
+}
+
+// AWS credentials the resource acquires ECR credentials with
+type EcrCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+
+	// Optional. The session token of temporary AWS credentials
+	SessionToken string
+
+	// Optional. The role assumed with the AWS credentials to access the registry
+	RoleArn string
+}
+
+func (ec *EcrCredentials) Validate() error {
+	if ec.AccessKeyId == "" || ec.SecretAccessKey == "" {
+		return fmt.Errorf("ECR AccessKeyId and SecretAccessKey make sense only as pair")
+	}
+	return nil
+}
+
+func (ec *EcrCredentials) ImageSource(source *resource.ImageSource, registry *Registry) {
+	source.AwsAccessKeyID = ec.AccessKeyId
+	source.AwsSecretAccessKey = ec.SecretAccessKey
+	source.AwsSessionToken = ec.SessionToken
+	source.AwsRoleArn = ec.RoleArn
+}
+
+func (ec *EcrCredentials) RegistryImageSource(source *resource.RegistryImageSource, registry *Registry) {
+	source.AwsAccessKeyID = ec.AccessKeyId
+	source.AwsSecretAccessKey = ec.SecretAccessKey
+	source.AwsSessionToken = ec.SessionToken
+	source.AwsRoleArn = ec.RoleArn
+	source.AwsRegion = registry.Region()
+}
+
+// Google Container Registry credentials, the JSON key of a service account
+type GcrCredentials struct {
+	JsonKey string
+}
+
+// The user GCR expects with a service account JSON key as password
+const gcrJsonKeyUser = "_json_key"
+
+func (gc *GcrCredentials) Validate() error {
+	if gc.JsonKey == "" {
+		return fmt.Errorf("GCR credentials need a JsonKey")
+	}
+	return nil
+}
+
+func (gc *GcrCredentials) ImageSource(source *resource.ImageSource, registry *Registry) {
+	source.Username = gcrJsonKeyUser
+	source.Password = gc.JsonKey
+}
+
+func (gc *GcrCredentials) RegistryImageSource(source *resource.RegistryImageSource, registry *Registry) {
+	source.Username = gcrJsonKeyUser
+	source.Password = gc.JsonKey
+}
@@ -0,0 +1,311 @@
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var dockerfileVariable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var dockerfileStageName = regexp.MustCompile(`^[a-z][a-z0-9_.-]*$`)
+
+// An instruction of a Dockerfile stage
+type IDockerfileInstruction interface {
+	// Checks the instruction, stages are the names of the stages before the one of the instruction
+	Validate(stages []string) error
+
+	String() string
+}
+
+type DockerfileInstructions []IDockerfileInstruction
+
+// RUN of commands joined with &&
+type RunInstruction struct {
+	Commands []string
+}
+
+func (ri *RunInstruction) Validate(stages []string) error {
+	if len(ri.Commands) == 0 {
+		return fmt.Errorf("RUN has no commands")
+	}
+
+	for _, command := range ri.Commands {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("RUN has an empty command")
+		}
+		if strings.ContainsAny(command, "\n\r") {
+			return fmt.Errorf("RUN command %q has a new line, use separate commands instead", command)
+		}
+	}
+	return nil
+}
+
+func (ri *RunInstruction) String() string {
+	return "RUN " + strings.Join(ri.Commands, " \\\n    && ")
+}
+
+// ENV of a single variable
+type EnvInstruction struct {
+	Name  string
+	Value string
+}
+
+func (ei *EnvInstruction) Validate(stages []string) error {
+	if !dockerfileVariable.MatchString(ei.Name) {
+		return fmt.Errorf("ENV has invalid variable name %q", ei.Name)
+	}
+	if strings.ContainsAny(ei.Value, "\n\r") {
+		return fmt.Errorf("ENV %s has a new line in its value", ei.Name)
+	}
+	return nil
+}
+
+func (ei *EnvInstruction) String() string {
+	value := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(ei.Value)
+	return fmt.Sprintf(`ENV %s="%s"`, ei.Name, value)
+}
+
+// ARG with an optional default value
+type ArgInstruction struct {
+	Name    string
+	Default string
+}
+
+func (ai *ArgInstruction) Validate(stages []string) error {
+	if !dockerfileVariable.MatchString(ai.Name) {
+		return fmt.Errorf("ARG has invalid variable name %q", ai.Name)
+	}
+	if strings.ContainsAny(ai.Default, "\n\r") {
+		return fmt.Errorf("ARG %s has a new line in its default value", ai.Name)
+	}
+	return nil
+}
+
+func (ai *ArgInstruction) String() string {
+	if ai.Default == "" {
+		return "ARG " + ai.Name
+	}
+	value := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(ai.Default)
+	return fmt.Sprintf(`ARG %s="%s"`, ai.Name, value)
+}
+
+// COPY from the build context or from a previous stage
+type CopyInstruction struct {
+	// The stage to copy from, the build context if empty
+	From string
+
+	Sources     []string
+	Destination string
+}
+
+func (ci *CopyInstruction) Validate(stages []string) error {
+	if len(ci.Sources) == 0 {
+		return fmt.Errorf("COPY has no sources")
+	}
+	if ci.Destination == "" {
+		return fmt.Errorf("COPY has no destination")
+	}
+
+	for _, path := range append(append([]string(nil), ci.Sources...), ci.Destination) {
+		if path == "" || strings.ContainsAny(path, "\n\r") {
+			return fmt.Errorf("COPY has invalid path %q", path)
+		}
+	}
+
+	if ci.From == "" {
+		return nil
+	}
+
+	for _, stage := range stages {
+		if stage == ci.From {
+			return nil
+		}
+	}
+	return fmt.Errorf("COPY from unknown stage %s", ci.From)
+}
+
+func (ci *CopyInstruction) String() string {
+	paths, err := json.Marshal(append(append([]string(nil), ci.Sources...), ci.Destination))
+	if err != nil {
+		panic(err.Error())
+	}
+
+	if ci.From == "" {
+		return "COPY " + string(paths)
+	}
+	return fmt.Sprintf("COPY --from=%s %s", ci.From, paths)
+}
+
+// USER with an optional group, user:group
+type UserInstruction struct {
+	User string
+}
+
+func (ui *UserInstruction) Validate(stages []string) error {
+	if ui.User == "" || strings.ContainsAny(ui.User, " \t\n\r") {
+		return fmt.Errorf("USER has invalid user %q", ui.User)
+	}
+	return nil
+}
+
+func (ui *UserInstruction) String() string {
+	return "USER " + ui.User
+}
+
+// A stage of a Dockerfile, it starts with FROM
+type DockerfileStage struct {
+	// The name other stages copy from, the last stage has none
+	Name string
+
+	// The image the stage starts from. The last stage starts from the From image of the build
+	// and leaves it empty.
+	From string
+
+	Instructions DockerfileInstructions
+}
+
+func (ds *DockerfileStage) add(instruction IDockerfileInstruction) *DockerfileStage {
+	ds.Instructions = append(ds.Instructions, instruction)
+	return ds
+}
+
+func (ds *DockerfileStage) Run(commands ...string) *DockerfileStage {
+	return ds.add(&RunInstruction{Commands: commands})
+}
+
+func (ds *DockerfileStage) Env(name, value string) *DockerfileStage {
+	return ds.add(&EnvInstruction{Name: name, Value: value})
+}
+
+func (ds *DockerfileStage) Arg(name, defaultValue string) *DockerfileStage {
+	return ds.add(&ArgInstruction{Name: name, Default: defaultValue})
+}
+
+func (ds *DockerfileStage) Copy(destination string, sources ...string) *DockerfileStage {
+	return ds.add(&CopyInstruction{Sources: sources, Destination: destination})
+}
+
+func (ds *DockerfileStage) CopyFrom(stage string, destination string, sources ...string) *DockerfileStage {
+	return ds.add(&CopyInstruction{From: stage, Sources: sources, Destination: destination})
+}
+
+func (ds *DockerfileStage) User(user string) *DockerfileStage {
+	return ds.add(&UserInstruction{User: user})
+}
+
+func (ds *DockerfileStage) render(builder *strings.Builder) {
+	for _, instruction := range ds.Instructions {
+		builder.WriteString(instruction.String())
+		builder.WriteString("\n")
+	}
+}
+
+// A Dockerfile built in go and validated when the pipeline is generated.
+// All stages but the last one are build stages, the last stage builds the image from the From image.
+type Dockerfile struct {
+	Stages []*DockerfileStage
+}
+
+// Adds a build stage
+func (d *Dockerfile) Stage(name string, from string) *DockerfileStage {
+	stage := &DockerfileStage{
+		Name: name,
+		From: from,
+	}
+	d.Stages = append(d.Stages, stage)
+	return stage
+}
+
+// The last stage, the one that builds the image from the From image. It is added if missing.
+func (d *Dockerfile) Final() *DockerfileStage {
+	if len(d.Stages) > 0 {
+		last := d.Stages[len(d.Stages)-1]
+		if last.From == "" && last.Name == "" {
+			return last
+		}
+	}
+	return d.Stage("", "")
+}
+
+func (d *Dockerfile) Validate() error {
+	if len(d.Stages) == 0 {
+		return fmt.Errorf("Dockerfile has no stages")
+	}
+
+	var names []string
+	for i, stage := range d.Stages {
+		last := i == len(d.Stages)-1
+
+		if last && (stage.From != "" || stage.Name != "") {
+			return fmt.Errorf("The last Dockerfile stage %s must build from the From image and have no name", stage.Name)
+		}
+		if !last && stage.From == "" {
+			return fmt.Errorf("Dockerfile stage %d has no image to build from", i)
+		}
+		if !last && !dockerfileStageName.MatchString(stage.Name) {
+			return fmt.Errorf("Dockerfile stage %d has invalid name %q", i, stage.Name)
+		}
+		if stage.From != "" && strings.ContainsAny(stage.From, " \t\n\r") {
+			return fmt.Errorf("Dockerfile stage %s has invalid image %q", stage.Name, stage.From)
+		}
+
+		for _, name := range names {
+			if name == stage.Name {
+				return fmt.Errorf("Dockerfile has stage %s twice", stage.Name)
+			}
+		}
+
+		for _, instruction := range stage.Instructions {
+			if err := instruction.Validate(names); err != nil {
+				return fmt.Errorf("Dockerfile stage %d: %s", i, err.Error())
+			}
+		}
+
+		if stage.Name != "" {
+			names = append(names, stage.Name)
+		}
+	}
+	return nil
+}
+
+// Renders the build stages and the instructions of the last stage. The build writes the FROM
+// clause of the From image between them.
+func (d *Dockerfile) Render() (stages string, steps string, err error) {
+	err = d.Validate()
+	if err != nil {
+		return "", "", err
+	}
+
+	builder := &strings.Builder{}
+	for _, stage := range d.Stages[:len(d.Stages)-1] {
+		fmt.Fprintf(builder, "FROM %s AS %s\n", stage.From, stage.Name)
+		stage.render(builder)
+		builder.WriteString("\n")
+	}
+	stages = builder.String()
+
+	builder = &strings.Builder{}
+	d.Stages[len(d.Stages)-1].render(builder)
+	steps = builder.String()
+
+	return stages, steps, nil
+}
+
+func (d *Dockerfile) MustRender() (stages string, steps string) {
+	stages, steps, err := d.Render()
+	if err != nil {
+		panic(err.Error())
+	}
+	return stages, steps
+}
+
+// The whole Dockerfile with the From image of the last stage
+func (d *Dockerfile) Text(from string) (string, error) {
+	stages, steps, err := d.Render()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%sFROM %s\n%s", stages, from, steps), nil
+}
@@ -0,0 +1,161 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerfileRender(t *testing.T) {
+	dockerfile := &Dockerfile{}
+	dockerfile.Stage("build", "golang:1.10").
+		Arg("VERSION", "dev").
+		Copy("/go/src/app/", "main.go", "go files/").
+		Run("set -ex", "go build -o /app .")
+
+	dockerfile.Final().
+		Env("GREETING", `say "hi" \ bye`).
+		CopyFrom("build", "/usr/local/bin/", "/app").
+		User("app:app")
+
+	stages, steps, err := dockerfile.Render()
+	require.NoError(t, err)
+
+	assert.Equal(t, `FROM golang:1.10 AS build
+ARG VERSION="dev"
+COPY ["main.go","go files/","/go/src/app/"]
+RUN set -ex \
+    && go build -o /app .
+
+`, stages)
+
+	assert.Equal(t, `ENV GREETING="say \"hi\" \\ bye"
+COPY --from=build ["/app","/usr/local/bin/"]
+USER app:app
+`, steps)
+
+	text, err := dockerfile.Text("ubuntu:16.04")
+	require.NoError(t, err)
+	assert.Equal(t, stages+"FROM ubuntu:16.04\n"+steps, text)
+}
+
+func TestDockerfileFinal(t *testing.T) {
+	dockerfile := &Dockerfile{}
+	assert.Equal(t, dockerfile.Final(), dockerfile.Final())
+
+	dockerfile.Final().Run("true")
+	stages, steps := dockerfile.MustRender()
+	assert.Empty(t, stages)
+	assert.Equal(t, "RUN true\n", steps)
+}
+
+func TestDockerfileValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func(dockerfile *Dockerfile)
+		result string
+	}{
+		{
+			"no stages",
+			func(dockerfile *Dockerfile) {},
+			"Dockerfile has no stages",
+		},
+		{
+			"build stage last",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Stage("build", "golang")
+			},
+			"The last Dockerfile stage build must build from the From image and have no name",
+		},
+		{
+			"build stage without image",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Stage("build", "")
+				dockerfile.Final()
+			},
+			"Dockerfile stage 0 has no image to build from",
+		},
+		{
+			"invalid stage name",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Stage("Build", "golang")
+				dockerfile.Final()
+			},
+			`Dockerfile stage 0 has invalid name "Build"`,
+		},
+		{
+			"duplicated stage",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Stage("build", "golang")
+				dockerfile.Stage("build", "golang")
+				dockerfile.Final()
+			},
+			"Dockerfile has stage build twice",
+		},
+		{
+			"copy from unknown stage",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Final().CopyFrom("build", "/", "/app")
+			},
+			"Dockerfile stage 0: COPY from unknown stage build",
+		},
+		{
+			"copy from a later stage",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Stage("first", "golang").CopyFrom("second", "/", "/app")
+				dockerfile.Stage("second", "golang")
+				dockerfile.Final()
+			},
+			"Dockerfile stage 0: COPY from unknown stage second",
+		},
+		{
+			"copy without sources",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Final().Copy("/")
+			},
+			"Dockerfile stage 0: COPY has no sources",
+		},
+		{
+			"empty run",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Final().Run()
+			},
+			"Dockerfile stage 0: RUN has no commands",
+		},
+		{
+			"multiline run",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Final().Run("apt-get update\napt-get install curl")
+			},
+			`Dockerfile stage 0: RUN command "apt-get update\napt-get install curl" has a new line, use separate commands instead`,
+		},
+		{
+			"invalid env",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Final().Env("FLY VERSION", "1")
+			},
+			`Dockerfile stage 0: ENV has invalid variable name "FLY VERSION"`,
+		},
+		{
+			"invalid arg",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Final().Arg("1VERSION", "")
+			},
+			`Dockerfile stage 0: ARG has invalid variable name "1VERSION"`,
+		},
+		{
+			"invalid user",
+			func(dockerfile *Dockerfile) {
+				dockerfile.Final().User("")
+			},
+			`Dockerfile stage 0: USER has invalid user ""`,
+		},
+	}
+
+	for _, test := range tests {
+		dockerfile := &Dockerfile{}
+		test.build(dockerfile)
+		assert.EqualError(t, dockerfile.Validate(), test.result, test.name)
+	}
+}
@@ -0,0 +1,20 @@
+package image
+
+import (
+	"time"
+
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+)
+
+// The image of the task that builds images with the OciBackend
+var OciBuildTask = &project.Resource{
+	Name: "oci-build-task-image",
+	Type: resource.ImageResourceType.Name,
+	Source: &Source{
+		Registry:   DockerHub,
+		Repository: "concourse/oci-build-task",
+	},
+	CheckInterval: model.Duration(24 * time.Hour),
+}
@@ -0,0 +1,37 @@
+package image
+
+// A platform an image is built for
+type Platform struct {
+	OS           string
+	Architecture string
+
+	// Only workers with all the tags build the images of the platform
+	WorkerTags []string
+}
+
+func (p *Platform) String() string {
+	return p.OS + "/" + p.Architecture
+}
+
+type Platforms []*Platform
+
+func (p Platforms) String() string {
+	result := ""
+	for i, platform := range p {
+		if i > 0 {
+			result += ","
+		}
+		result += platform.String()
+	}
+	return result
+}
+
+var LinuxAmd64 = &Platform{
+	OS:           "linux",
+	Architecture: "amd64",
+}
+
+var LinuxArm64 = &Platform{
+	OS:           "linux",
+	Architecture: "arm64",
+}
@@ -14,6 +14,9 @@ type PutParams struct {
 	BuildArgs map[string]interface{}
 	Load      bool
 	FromImage *project.JobResource
+
+	// A file with the tags pushed in addition to the scoped tag, optional
+	AdditionalTags IBuild
 }
 
 func (ipp *PutParams) ModelParams() interface{} {
@@ -26,6 +29,10 @@ func (ipp *PutParams) ModelParams() interface{} {
 		params.LoadBase = ipp.FromImage.Path()
 	}
 
+	if ipp.AdditionalTags != nil {
+		params.AdditionalTags = ipp.AdditionalTags.Path()
+	}
+
 	return params
 }
 
@@ -40,5 +47,44 @@ func (ipp *PutParams) InputResources() project.JobResources {
 		resources = append(resources, ipp.FromImage)
 	}
 
+	if res, ok := ipp.AdditionalTags.(project.IInputResource); ok {
+		resources = append(resources, res.InputResources()...)
+	}
+
+	return resources
+}
+
+// Put params of the images built with the OciBackend
+type OciPutParams struct {
+	// The OCI image tarball
+	Image IBuild
+
+	// A file with the tags pushed in addition to the scoped tag, optional
+	AdditionalTags IBuild
+}
+
+func (opp *OciPutParams) ModelParams() interface{} {
+	params := &resource.RegistryImagePutParams{
+		Image: opp.Image.Path(),
+	}
+
+	if opp.AdditionalTags != nil {
+		params.AdditionalTags = opp.AdditionalTags.Path()
+	}
+
+	return params
+}
+
+func (opp *OciPutParams) InputResources() project.JobResources {
+	var resources project.JobResources
+
+	if res, ok := opp.Image.(project.IInputResource); ok {
+		resources = append(resources, res.InputResources()...)
+	}
+
+	if res, ok := opp.AdditionalTags.(project.IInputResource); ok {
+		resources = append(resources, res.InputResources()...)
+	}
+
 	return resources
 }
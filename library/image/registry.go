@@ -1,13 +1,123 @@
 package image
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+)
+
+// How the images of a registry are built and rendered
+type Backend int
+
+const (
+	// docker-image resources, the image is built by their put on privileged workers
+	DockerImageBackend Backend = iota
+
+	// registry-image resources, the image is built by a privileged oci-build-task task
+	OciBackend
+)
+
 type Registry struct {
-	Domain             string
+	Domain string
+
+	// The credentials the registry is accessed with, the registry is public if nil
+	Credentials ICredentials
+
+	// Shorthand for ECR credentials, used when Credentials is nil
 	AwsAccessKeyId     string
 	AwsSecretAccessKey string
+
+	// The region of the ECR registry, taken from the domain if empty
+	AwsRegion string
+
+	// The registry is accessed over plain HTTP or with an unverified certificate.
+	// Only the DockerImageBackend supports insecure registries.
+	Insecure bool
+
+	// PEM encoded CA certificates the certificate of the registry is verified with
+	CaCerts []string
+
+	// How the images of the registry are built and rendered
+	Backend Backend
+
+	// The platforms the images of the registry are built for, the platform of the worker by default.
+	// Only the OciBackend builds for platforms.
+	Platforms Platforms
+}
+
+// The credentials of the registry, the ECR shorthand turned into EcrCredentials
+func (ir *Registry) credentials() ICredentials {
+	if ir.Credentials != nil {
+		return ir.Credentials
+	}
+
+	if ir.AwsAccessKeyId != "" || ir.AwsSecretAccessKey != "" {
+		return &EcrCredentials{
+			AccessKeyId:     ir.AwsAccessKeyId,
+			SecretAccessKey: ir.AwsSecretAccessKey,
+		}
+	}
+	return nil
+}
+
+// The ECR credentials of the registry, nil if the registry is not accessed with ECR credentials
+func (ir *Registry) Ecr() *EcrCredentials {
+	ecr, _ := ir.credentials().(*EcrCredentials)
+	return ecr
 }
 
 func (ir *Registry) Public() bool {
-	return ir.AwsAccessKeyId == "" && ir.AwsSecretAccessKey == ""
+	return ir.credentials() == nil
+}
+
+func (ir *Registry) Validate() error {
+	if ir.Credentials != nil && (ir.AwsAccessKeyId != "" || ir.AwsSecretAccessKey != "") {
+		return fmt.Errorf("Registry %s has both Credentials and AWS keys", ir.Domain)
+	}
+
+	if credentials := ir.credentials(); credentials != nil {
+		if err := credentials.Validate(); err != nil {
+			return fmt.Errorf("Registry %s: %s", ir.Domain, err.Error())
+		}
+	}
+
+	if ir.Insecure && ir.Backend == OciBackend {
+		return fmt.Errorf("Registry %s is insecure, the OCI backend supports only CaCerts", ir.Domain)
+	}
+	return nil
+}
+
+// The type of the resources of the registry images
+func (ir *Registry) ResourceType() project.ResourceTypeName {
+	if ir.Backend == OciBackend {
+		return resource.RegistryImageResourceType.Name
+	}
+	return resource.ImageResourceType.Name
+}
+
+// The get params that fetch the images of the registry as an archive and the file of the archive
+func (ir *Registry) ArchiveGetParams() (interface{}, string) {
+	if ir.Backend == OciBackend {
+		return &resource.RegistryImageGetParams{Format: "oci"}, "image.tar"
+	}
+	return &resource.ImageGetParams{Save: true}, "image"
+}
+
+// The region of the ECR registry, the domain is <account>.dkr.ecr.<region>.amazonaws.com
+func (ir *Registry) Region() string {
+	if ir.AwsRegion != "" {
+		return ir.AwsRegion
+	}
+
+	parts := strings.Split(ir.Domain, ".")
+	for i, part := range parts {
+		if part == "ecr" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
 }
 
 var DockerHub = &Registry{
@@ -1,7 +1,6 @@
 package image
 
 import (
-	"fmt"
 	"path"
 
 	"github.com/concourse-friends/concourse-builder/project"
@@ -12,6 +11,13 @@ type Source struct {
 	Registry   *Registry
 	Repository string
 	Tag        Tag
+
+	// The platform fetched from a multi-platform image, the one of the worker if nil
+	Platform *Platform
+}
+
+func (im *Source) Validate() error {
+	return im.Registry.Validate()
 }
 
 func (im *Source) ModelSource(scope project.Scope, info *project.ScopeInfo) interface{} {
@@ -20,28 +26,47 @@ func (im *Source) ModelSource(scope project.Scope, info *project.ScopeInfo) inte
 		repository = path.Join(im.Registry.Domain, repository)
 	}
 
-	tag := info.Scope(scope, "-")
-	if tag != "" && im.Tag == "" {
-		tag = tag[:len(tag)-1]
-	} else {
-		tag = tag + string(im.Tag)
+	tag := string(ConvertToImageTag(info.Name(scope, "-", string(im.Tag))))
+	credentials := im.Registry.credentials()
+
+	if im.Registry.Backend == OciBackend {
+		source := &resource.RegistryImageSource{
+			Repository: repository,
+			Tag:        tag,
+			CaCerts:    im.Registry.CaCerts,
+		}
+
+		if credentials != nil {
+			credentials.RegistryImageSource(source, im.Registry)
+		}
+
+		if im.Platform != nil {
+			source.Platform = &resource.RegistryImagePlatform{
+				OS:           im.Platform.OS,
+				Architecture: im.Platform.Architecture,
+			}
+		}
+		return source
 	}
 
 	source := &resource.ImageSource{
 		Repository: repository,
-		Tag:        string(ConvertToImageTag(tag)),
+		Tag:        tag,
 	}
 
-	if im.Registry.AwsAccessKeyId != "" || im.Registry.AwsSecretAccessKey != "" {
-		if im.Registry.AwsAccessKeyId == "" || im.Registry.AwsSecretAccessKey == "" {
-			return fmt.Errorf(
-				"For ImageRegistry AwsAccessKeyId and AwsSecretAccessKey make sense only as pair",
-				im.Registry.Domain)
-		}
+	if credentials != nil {
+		credentials.ImageSource(source, im.Registry)
+	}
 
-		source.AwsAccessKeyID = im.Registry.AwsAccessKeyId
-		source.AwsSecretAccessKey = im.Registry.AwsSecretAccessKey
+	if im.Registry.Insecure {
+		source.InsecureRegistries = []string{im.Registry.Domain}
 	}
 
+	for _, cert := range im.Registry.CaCerts {
+		source.CaCerts = append(source.CaCerts, &resource.ImageCaCert{
+			Domain: im.Registry.Domain,
+			Cert:   cert,
+		})
+	}
 	return source
 }
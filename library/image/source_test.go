@@ -0,0 +1,180 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+var testInfo = &project.ScopeInfo{
+	Installation: "installation",
+	Team:         "team",
+	Pipeline:     "pipeline",
+}
+
+func TestSourceCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry *Registry
+		image    *resource.ImageSource
+		oci      *resource.RegistryImageSource
+	}{
+		{
+			"public",
+			&Registry{},
+			&resource.ImageSource{},
+			&resource.RegistryImageSource{},
+		},
+		{
+			"ecr shorthand",
+			&Registry{
+				Domain:             "123.dkr.ecr.eu-west-1.amazonaws.com",
+				AwsAccessKeyId:     "key",
+				AwsSecretAccessKey: "secret",
+			},
+			&resource.ImageSource{
+				AwsAccessKeyID:     "key",
+				AwsSecretAccessKey: "secret",
+			},
+			&resource.RegistryImageSource{
+				AwsAccessKeyID:     "key",
+				AwsSecretAccessKey: "secret",
+				AwsRegion:          "eu-west-1",
+			},
+		},
+		{
+			"ecr role",
+			&Registry{
+				Domain: "123.dkr.ecr.us-east-1.amazonaws.com",
+				Credentials: &EcrCredentials{
+					AccessKeyId:     "key",
+					SecretAccessKey: "secret",
+					RoleArn:         "arn:aws:iam::123:role/pull",
+				},
+			},
+			&resource.ImageSource{
+				AwsAccessKeyID:     "key",
+				AwsSecretAccessKey: "secret",
+				AwsRoleArn:         "arn:aws:iam::123:role/pull",
+			},
+			&resource.RegistryImageSource{
+				AwsAccessKeyID:     "key",
+				AwsSecretAccessKey: "secret",
+				AwsRoleArn:         "arn:aws:iam::123:role/pull",
+				AwsRegion:          "us-east-1",
+			},
+		},
+		{
+			"gcr",
+			&Registry{
+				Domain:      "gcr.io",
+				Credentials: &GcrCredentials{JsonKey: "{}"},
+			},
+			&resource.ImageSource{
+				Username: "_json_key",
+				Password: "{}",
+			},
+			&resource.RegistryImageSource{
+				Username: "_json_key",
+				Password: "{}",
+			},
+		},
+		{
+			"harbor",
+			&Registry{
+				Domain:      "harbor.local",
+				Credentials: &UsernamePassword{Username: "robot", Password: "token"},
+				CaCerts:     []string{"PEM"},
+			},
+			&resource.ImageSource{
+				Username: "robot",
+				Password: "token",
+				CaCerts:  []*resource.ImageCaCert{{Domain: "harbor.local", Cert: "PEM"}},
+			},
+			&resource.RegistryImageSource{
+				Username: "robot",
+				Password: "token",
+				CaCerts:  []string{"PEM"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		source := &Source{
+			Registry:   test.registry,
+			Repository: "app",
+		}
+		assert.NoError(t, source.Validate(), test.name)
+
+		repository := "app"
+		if test.registry.Domain != "" {
+			repository = test.registry.Domain + "/app"
+		}
+
+		test.image.Repository = repository
+		test.image.Tag = "installation-team-pipeline"
+		assert.Equal(t, test.image, source.ModelSource(project.IsolatedPipelineScope, testInfo), test.name)
+
+		test.registry.Backend = OciBackend
+		test.oci.Repository = repository
+		test.oci.Tag = "installation-team-pipeline"
+		assert.Equal(t, test.oci, source.ModelSource(project.IsolatedPipelineScope, testInfo), test.name)
+	}
+}
+
+func TestSourceInsecure(t *testing.T) {
+	source := &Source{
+		Registry: &Registry{
+			Domain:   "registry.local:5000",
+			Insecure: true,
+		},
+		Repository: "app",
+	}
+	assert.NoError(t, source.Validate())
+
+	rendered := source.ModelSource(project.UniverseScope, testInfo).(*resource.ImageSource)
+	assert.Equal(t, []string{"registry.local:5000"}, rendered.InsecureRegistries)
+
+	source.Registry.Backend = OciBackend
+	assert.EqualError(t, source.Validate(),
+		"Registry registry.local:5000 is insecure, the OCI backend supports only CaCerts")
+}
+
+func TestRegistryValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry *Registry
+		result   string
+	}{
+		{
+			"half aws pair",
+			&Registry{Domain: "ecr", AwsAccessKeyId: "key"},
+			"Registry ecr: ECR AccessKeyId and SecretAccessKey make sense only as pair",
+		},
+		{
+			"credentials and aws keys",
+			&Registry{
+				Domain:         "ecr",
+				AwsAccessKeyId: "key",
+				Credentials:    &UsernamePassword{Username: "user", Password: "password"},
+			},
+			"Registry ecr has both Credentials and AWS keys",
+		},
+		{
+			"username without password",
+			&Registry{Domain: "acr", Credentials: &UsernamePassword{Username: "user"}},
+			"Registry acr: Username and Password make sense only as pair",
+		},
+		{
+			"gcr without key",
+			&Registry{Domain: "gcr.io", Credentials: &GcrCredentials{}},
+			"Registry gcr.io: GCR credentials need a JsonKey",
+		},
+	}
+
+	for _, test := range tests {
+		assert.EqualError(t, test.registry.Validate(), test.result, test.name)
+	}
+}
@@ -2,6 +2,8 @@ package image
 
 import (
 	"strings"
+
+	"github.com/concourse-friends/concourse-builder/project"
 )
 
 type Tag string
@@ -11,3 +13,22 @@ func ConvertToImageTag(raw string) Tag {
 	tag = strings.Replace(tag, "/", "_", -1)
 	return Tag(tag)
 }
+
+// The prefix of the image tags of a scope, resolved when the pipeline is rendered
+type ScopedTagPrefix struct {
+	Scope project.Scope
+}
+
+func (stp *ScopedTagPrefix) ScopedValue(info *project.ScopeInfo) string {
+	return string(ConvertToImageTag(info.Scope(stp.Scope, "-")))
+}
+
+// The tag of an image resource in the scope the pipeline is rendered in
+type ScopedTag struct {
+	Image *project.Resource
+}
+
+func (st *ScopedTag) ScopedValue(info *project.ScopeInfo) string {
+	tag := st.Image.Source.(*Source).Tag
+	return string(ConvertToImageTag(info.WithNaming(st.Image.Naming).Name(st.Image.Scope, "-", string(tag))))
+}
@@ -0,0 +1,47 @@
+package image
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+var validTag = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// Tags pushed in addition to the scoped tag of the image source. The scoped tag is always pushed,
+// the extra tags are prefixed with it, so the scopes sharing a repository do not overwrite their tags
+// and the tags are pruned together with the scoped tag.
+type TagPolicy struct {
+	// Fixed tags, latest for example
+	Tags []Tag
+
+	// Files with whitespace separated tags, a version file written by a task for example
+	Files []project.IValue
+
+	// Git inputs the commit SHA of which is a tag
+	GitRefs []*project.JobResource
+
+	// Use the short commit SHA of the git inputs
+	ShortGitRefs bool
+
+	// Tag with the UTC time of the build, 20060102150405
+	Timestamp bool
+
+	// Push the tags as they are, without the scoped tag prefix. Only for repositories of a single scope,
+	// the images of the scopes sharing the repository overwrite the tags and the tags are never pruned.
+	Unscoped bool
+}
+
+func (tp *TagPolicy) Validate() error {
+	for _, tag := range tp.Tags {
+		if !validTag.MatchString(string(tag)) {
+			return fmt.Errorf("Invalid image tag %q", tag)
+		}
+	}
+
+	if len(tp.Tags) == 0 && len(tp.Files) == 0 && len(tp.GitRefs) == 0 && !tp.Timestamp {
+		return fmt.Errorf("The tag policy has no tags")
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package image
+
+import (
+	"time"
+
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+)
+
+// The image of the trivy vulnerability scanner
+var Trivy = &project.Resource{
+	Name: "trivy-image",
+	Type: resource.ImageResourceType.Name,
+	Source: &Source{
+		Registry:   DockerHub,
+		Repository: "aquasec/trivy",
+	},
+	CheckInterval: model.Duration(24 * time.Hour),
+}
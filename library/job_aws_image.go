@@ -17,7 +17,6 @@ import (
 	"github.com/concourse-friends/concourse-builder/library/image"
 	"github.com/concourse-friends/concourse-builder/library/primitive"
 	"github.com/concourse-friends/concourse-builder/project"
-	"github.com/concourse-friends/concourse-builder/resource"
 )
 
 type AwsImageJobArgs struct {
@@ -38,7 +37,7 @@ func AwsImageJob(args *AwsImageJobArgs) *project.Resource {
 
 	imageResource = &project.Resource{
 		Name:  resourceName,
-		Type:  resource.ImageResourceType.Name,
+		Type:  args.ImageRegistry.ResourceType(),
 		Scope: project.AllTeamsScope,
 		Source: &image.Source{
 			Registry:   args.ImageRegistry,
@@ -52,7 +51,7 @@ func AwsImageJob(args *AwsImageJobArgs) *project.Resource {
 		RelativePath: "docker/aws",
 	}
 
-	job := BuildImage(
+	job := MustBuildImage(
 		&BuildImageArgs{
 			ResourceRegistry:   args.ResourceRegistry,
 			PrepareImage:       image.Ubuntu,
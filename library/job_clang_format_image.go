@@ -4,7 +4,6 @@ import (
 	"github.com/concourse-friends/concourse-builder/library/image"
 	"github.com/concourse-friends/concourse-builder/library/primitive"
 	"github.com/concourse-friends/concourse-builder/project"
-	"github.com/concourse-friends/concourse-builder/resource"
 )
 
 type CLangFormatImageJobArgs struct {
@@ -22,7 +21,7 @@ func CLangFormatImageJob(args *CLangFormatImageJobArgs) *project.Resource {
 
 	imageResource = &project.Resource{
 		Name:  resourceName,
-		Type:  resource.ImageResourceType.Name,
+		Type:  args.ImageRegistry.ResourceType(),
 		Scope: project.TeamScope,
 		Source: &image.Source{
 			Registry:   args.ImageRegistry,
@@ -35,7 +34,7 @@ func CLangFormatImageJob(args *CLangFormatImageJobArgs) *project.Resource {
 		RelativePath: "docker/clang-format",
 	}
 
-	job := BuildImage(
+	job := MustBuildImage(
 		&BuildImageArgs{
 			ResourceRegistry:   args.ResourceRegistry,
 			PrepareImage:       image.Ubuntu,
@@ -3,7 +3,6 @@ package library
 import (
 	"github.com/concourse-friends/concourse-builder/library/image"
 	"github.com/concourse-friends/concourse-builder/project"
-	"github.com/concourse-friends/concourse-builder/resource"
 )
 
 type CurlImageJobArgs struct {
@@ -21,7 +20,7 @@ func CurlImageJob(args *CurlImageJobArgs) *project.Resource {
 
 	imageResource = &project.Resource{
 		Name:  resourceName,
-		Type:  resource.ImageResourceType.Name,
+		Type:  args.ImageRegistry.ResourceType(),
 		Scope: project.TeamScope,
 		Source: &image.Source{
 			Registry:   args.ImageRegistry,
@@ -29,22 +28,21 @@ func CurlImageJob(args *CurlImageJobArgs) *project.Resource {
 		},
 	}
 
-	steps := `RUN set -ex \
-# install curl \
-&& apt-get update \
-&& apt-get install -y curl \
-\
-# cleanup \
-&& apt-get clean \
-&& rm -rf /var/lib/apt/lists/*`
+	dockerfile := &image.Dockerfile{}
+	dockerfile.Final().Run(
+		"set -ex",
+		"apt-get update",
+		"apt-get install -y curl",
+		"apt-get clean",
+		"rm -rf /var/lib/apt/lists/*")
 
-	job := BuildImage(
+	job := MustBuildImage(
 		&BuildImageArgs{
 			ResourceRegistry: args.ResourceRegistry,
 			PrepareImage:     image.Ubuntu,
 			From:             args.LinuxImageResource,
 			Name:             "curl",
-			DockerFileSteps:  steps,
+			Dockerfile:       dockerfile,
 			Image:            imageResource,
 		})
 	job.AddToGroup(project.SystemGroup)
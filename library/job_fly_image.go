@@ -1,12 +1,9 @@
 package library
 
 import (
-	"fmt"
-
 	"github.com/concourse-friends/concourse-builder/library/image"
 	"github.com/concourse-friends/concourse-builder/library/primitive"
 	"github.com/concourse-friends/concourse-builder/project"
-	"github.com/concourse-friends/concourse-builder/resource"
 	"github.com/jinzhu/copier"
 )
 
@@ -32,7 +29,7 @@ func FlyImageJob(args *FlyImageJobArgs) *project.Resource {
 
 	imageResource = &project.Resource{
 		Name:  resourceName,
-		Type:  resource.ImageResourceType.Name,
+		Type:  args.ImageRegistry.ResourceType(),
 		Scope: project.AllTeamsScope,
 		Source: &image.Source{
 			Registry:   args.ImageRegistry,
@@ -44,15 +41,17 @@ func FlyImageJob(args *FlyImageJobArgs) *project.Resource {
 		Volume:       args.ResourceRegistry.JobResource(args.ConcourseBuilderGit, true, nil),
 		RelativePath: "docker/fly",
 	}
-	var insecureArg string
+
+	// The steps download fly from the concourse, so it always matches its version
+	buildArgs := map[string]interface{}{
+		"CONCOURSE_URL": args.Concourse.URL,
+	}
 
 	if args.Concourse.Insecure {
-		insecureArg = " -k"
+		buildArgs["CURL_OPTIONS"] = "--insecure"
 	}
-	evalFlyVersion := fmt.Sprintf("echo ENV FLY_VERSION=`curl %s/api/v1/info%s | "+
-		"awk -F ',' ' { print $1 } ' | awk -F ':' ' { print $2 } '`", args.Concourse.URL, insecureArg)
 
-	job := BuildImage(
+	job := MustBuildImage(
 		&BuildImageArgs{
 			ResourceRegistry:   args.ResourceRegistry,
 			PrepareImage:       curlImage,
@@ -60,7 +59,7 @@ func FlyImageJob(args *FlyImageJobArgs) *project.Resource {
 			Name:               "fly",
 			DockerFileResource: dockerSteps,
 			Image:              imageResource,
-			Eval:               evalFlyVersion,
+			BuildArgs:          buildArgs,
 		})
 	job.AddToGroup(project.SystemGroup)
 
@@ -0,0 +1,105 @@
+package library
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlyImageJobPlatforms(t *testing.T) {
+	registry := &image.Registry{
+		Domain:             "123.dkr.ecr.eu-west-1.amazonaws.com",
+		AwsAccessKeyId:     "key",
+		AwsSecretAccessKey: "secret",
+		Backend:            image.OciBackend,
+		Platforms: image.Platforms{
+			image.LinuxAmd64,
+			image.LinuxArm64,
+		},
+	}
+
+	pipeline := project.NewPipeline()
+	pipeline.Name = "images"
+
+	flyImage := FlyImageJob(&FlyImageJobArgs{
+		LinuxImageResource: image.Ubuntu,
+		ConcourseBuilderGit: &project.Resource{
+			Name: ConcourseBuilderGitName,
+			Type: resource.GitResourceType.Name,
+			Source: &GitSource{
+				Repo:   &primitive.GitRepo{URI: "git@github.com:concourse-friends/concourse-builder.git"},
+				Branch: &primitive.GitBranch{Name: "master"},
+			},
+		},
+		ImageRegistry:    registry,
+		ResourceRegistry: pipeline.ResourceRegistry,
+		Concourse:        &primitive.Concourse{URL: "http://concourse.com"},
+	})
+
+	pipeline.ResourceRegistry.MustRegister(flyImage)
+
+	jobs := flyImage.NeededJobs()
+	require.Len(t, jobs, 1)
+	pipeline.Jobs = project.Jobs{jobs[0]}
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	rendered := yml.String()
+
+	assert.Contains(t, rendered, "- name: fly-image")
+	assert.Contains(t, rendered, "task: build-amd64")
+	assert.Contains(t, rendered, "task: build-arm64")
+	assert.Contains(t, rendered, "IMAGE_PLATFORM: linux/arm64")
+	assert.Contains(t, rendered, "DOCKERFILE_DIR: concourse-builder-git/docker/fly")
+	assert.Contains(t, rendered, "PLATFORMS: linux/amd64 linux/arm64")
+
+	// The concourse is given as build args, it does not stay in the image
+	assert.Contains(t, rendered, "BUILD_ARG_CONCOURSE_URL: http://concourse.com")
+	assert.NotContains(t, rendered, "CURL_OPTIONS")
+
+	// The steps download the fly of the built platform, not a fixed one
+	steps, err := ioutil.ReadFile("../docker/fly/steps")
+	require.NoError(t, err)
+	assert.Contains(t, string(steps), "ARG TARGETARCH")
+	assert.Contains(t, string(steps), "arch=${ARCH}")
+	assert.NotContains(t, string(steps), "arch=amd64")
+	assert.Contains(t, string(steps), "ARG CONCOURSE_URL")
+	assert.Contains(t, string(steps), "ARG CURL_OPTIONS")
+	assert.NotContains(t, string(steps), "ENV")
+}
+
+func TestFlyImageJobInsecure(t *testing.T) {
+	pipeline := project.NewPipeline()
+	pipeline.Name = "images"
+
+	flyImage := FlyImageJob(&FlyImageJobArgs{
+		LinuxImageResource: image.Ubuntu,
+		ConcourseBuilderGit: &project.Resource{
+			Name: ConcourseBuilderGitName,
+			Type: resource.GitResourceType.Name,
+			Source: &GitSource{
+				Repo:   &primitive.GitRepo{URI: "git@github.com:concourse-friends/concourse-builder.git"},
+				Branch: &primitive.GitBranch{Name: "master"},
+			},
+		},
+		ImageRegistry:    image.DockerHub,
+		ResourceRegistry: pipeline.ResourceRegistry,
+		Concourse:        &primitive.Concourse{URL: "https://concourse.local", Insecure: true},
+	})
+
+	pipeline.ResourceRegistry.MustRegister(flyImage)
+	pipeline.Jobs = project.Jobs{flyImage.NeededJobs()[0]}
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	rendered := yml.String()
+
+	assert.Contains(t, rendered, "build_args:\n        CONCOURSE_URL: https://concourse.local\n        CURL_OPTIONS: --insecure\n")
+}
@@ -4,7 +4,6 @@ import (
 	"github.com/concourse-friends/concourse-builder/library/image"
 	"github.com/concourse-friends/concourse-builder/library/primitive"
 	"github.com/concourse-friends/concourse-builder/project"
-	"github.com/concourse-friends/concourse-builder/resource"
 	"github.com/jinzhu/copier"
 )
 
@@ -29,7 +28,7 @@ func GitImageJob(args *GitImageJobArgs) *project.Resource {
 
 	imageResource = &project.Resource{
 		Name:  resourceName,
-		Type:  resource.ImageResourceType.Name,
+		Type:  args.ImageRegistry.ResourceType(),
 		Scope: project.TeamScope,
 		Source: &image.Source{
 			Registry:   args.ImageRegistry,
@@ -42,7 +41,7 @@ func GitImageJob(args *GitImageJobArgs) *project.Resource {
 		RelativePath: "docker/git",
 	}
 
-	job := BuildImage(
+	job := MustBuildImage(
 		&BuildImageArgs{
 			ResourceRegistry:   args.ResourceRegistry,
 			PrepareImage:       image.Ubuntu,
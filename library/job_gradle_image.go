@@ -3,7 +3,6 @@ package library
 import (
 	"github.com/concourse-friends/concourse-builder/library/image"
 	"github.com/concourse-friends/concourse-builder/project"
-	"github.com/concourse-friends/concourse-builder/resource"
 )
 
 type GradleImageJobArgs struct {
@@ -21,7 +20,7 @@ func GradleImageJob(args *GradleImageJobArgs) *project.Resource {
 
 	imageResource = &project.Resource{
 		Name:  resourceName,
-		Type:  resource.ImageResourceType.Name,
+		Type:  args.ImageRegistry.ResourceType(),
 		Scope: project.TeamScope,
 		Source: &image.Source{
 			Registry:   args.ImageRegistry,
@@ -29,15 +28,16 @@ func GradleImageJob(args *GradleImageJobArgs) *project.Resource {
 		},
 	}
 
-	steps := `USER root`
+	dockerfile := &image.Dockerfile{}
+	dockerfile.Final().User("root")
 
-	job := BuildImage(
+	job := MustBuildImage(
 		&BuildImageArgs{
 			ResourceRegistry: args.ResourceRegistry,
 			PrepareImage:     image.Ubuntu,
 			From:             args.GradleImageResource,
 			Name:             "gradle",
-			DockerFileSteps:  steps,
+			Dockerfile:       dockerfile,
 			Image:            imageResource,
 		})
 	job.AddToGroup(project.SystemGroup)
@@ -0,0 +1,131 @@
+package library
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+	"github.com/jinzhu/copier"
+)
+
+type PruneImagesJobArgs struct {
+	LinuxImageResource  *project.Resource
+	ConcourseBuilderGit *project.Resource
+	ImageRegistry       *image.Registry
+	ResourceRegistry    *project.ResourceRegistry
+	Concourse           *primitive.Concourse
+
+	// The images which pipeline scoped tags are pruned, their registries have to be ECR
+	Images []*project.Resource
+
+	// The steps that write a <pipeline>.yml file for each live pipeline to Pipelines
+	PipelinesSteps project.ISteps
+	Pipelines      *project.TaskOutput
+
+	// The regular expression of the pipelines which tags are pruned. The pipeline of a tag is the longest
+	// of the -separated prefixes of the tag matching it, so a tag of pipeline foo-bar is not a tag of pipeline foo.
+	PipelineRegex string
+
+	// Only print the tags that would be pruned
+	DryRun bool
+
+	// How often the tags are pruned, once a day by default
+	Interval time.Duration
+}
+
+func taskPruneImage(args *PruneImagesJobArgs, awsImage *project.JobResource,
+	imageResource *project.Resource) (*project.TaskStep, error) {
+
+	imageSource, ok := imageResource.Source.(*image.Source)
+	if !ok {
+		return nil, fmt.Errorf("The pruned image %s is not an image", imageResource.Name)
+	}
+
+	ecr := imageSource.Registry.Ecr()
+	if ecr == nil {
+		return nil, fmt.Errorf("The pruned image %s is not in an ECR registry", imageResource.Name)
+	}
+
+	if ecr.RoleArn != "" {
+		return nil, fmt.Errorf("The pruned image %s is accessed with a role, it is not supported", imageResource.Name)
+	}
+
+	task := &project.TaskStep{
+		Platform: model.LinuxPlatform,
+		Name:     project.TaskName("prune-" + imageResource.Name),
+		Image:    awsImage,
+		Run: &primitive.Location{
+			Volume: &primitive.Directory{
+				Root: "/bin/aws",
+			},
+			RelativePath: "prune-scoped-ecr-images.sh",
+		},
+		Environment: map[string]interface{}{
+			"REPOSITORY": imageSource.Repository,
+			"TAG_PREFIX": &image.ScopedTagPrefix{
+				Scope: project.AllPipelinesScope,
+			},
+			"PIPELINES": &primitive.Location{
+				Volume: args.Pipelines,
+			},
+			"AWS_ACCESS_KEY_ID":     ecr.AccessKeyId,
+			"AWS_SECRET_ACCESS_KEY": ecr.SecretAccessKey,
+			"REGION":                imageSource.Registry.Region(),
+			"PIPELINE_REGEX":        args.PipelineRegex,
+		},
+	}
+
+	if ecr.SessionToken != "" {
+		task.Environment["AWS_SESSION_TOKEN"] = ecr.SessionToken
+	}
+
+	if args.DryRun {
+		task.Environment["DRY_RUN"] = "true"
+	}
+
+	return task, nil
+}
+
+// The job that prunes the pipeline scoped tags of the images that belong to no live pipeline.
+// It is triggered by a time resource.
+func PruneImagesJob(args *PruneImagesJobArgs) (*project.Job, error) {
+	if args.PipelineRegex == "" {
+		return nil, fmt.Errorf("The pruned pipelines need a PipelineRegex")
+	}
+
+	awsImageJobArgs := &AwsImageJobArgs{}
+	copier.Copy(awsImageJobArgs, args)
+
+	awsImage := AwsImageJob(awsImageJobArgs)
+	awsImageResource := args.ResourceRegistry.JobResource(awsImage, true, nil)
+
+	interval := args.Interval
+	if interval == 0 {
+		interval = 24 * time.Hour
+	}
+
+	steps := append(project.ISteps{}, args.PipelinesSteps...)
+	for _, imageResource := range args.Images {
+		task, err := taskPruneImage(args, awsImageResource, imageResource)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, task)
+	}
+
+	job := &project.Job{
+		Name:   project.JobName("prune-images"),
+		Groups: project.JobGroups{},
+		Steps:  steps,
+	}
+
+	job.RunOnSchedule(args.ResourceRegistry, resource.TimeResource("prune-images-timer", &resource.TimeSource{
+		Interval: model.Duration(interval),
+	}))
+
+	return job, nil
+}
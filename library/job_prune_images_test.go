@@ -0,0 +1,129 @@
+package library
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pruneImagesArgs(registry *image.Registry, images ...*project.Resource) *PruneImagesJobArgs {
+	resourceRegistry := project.NewResourceRegistry()
+
+	pipelines := &project.TaskOutput{
+		Directory: "pipelines",
+	}
+
+	return &PruneImagesJobArgs{
+		LinuxImageResource: image.Ubuntu,
+		ConcourseBuilderGit: &project.Resource{
+			Name: "concourse-builder-git",
+			Type: "git",
+			Source: &GitSource{
+				Repo:   &primitive.GitRepo{URI: "git@github.com:org/concourse-builder.git"},
+				Branch: &primitive.GitBranch{Name: "master"},
+			},
+		},
+		ImageRegistry:    registry,
+		ResourceRegistry: resourceRegistry,
+		Concourse:        &primitive.Concourse{},
+		Images:           images,
+		PipelinesSteps: project.ISteps{
+			&project.TaskStep{
+				Platform: model.LinuxPlatform,
+				Name:     "pipelines",
+				Image:    resourceRegistry.JobResource(image.Ubuntu, true, nil),
+				Run:      &dummyRun{},
+				Outputs:  []project.IOutput{pipelines},
+			},
+		},
+		Pipelines:     pipelines,
+		PipelineRegex: ".*-sdpb$",
+		DryRun:        true,
+	}
+}
+
+func TestPruneImagesJob(t *testing.T) {
+	registry := &image.Registry{
+		Domain:             "123.dkr.ecr.eu-west-1.amazonaws.com",
+		AwsAccessKeyId:     "key",
+		AwsSecretAccessKey: "secret",
+	}
+
+	app := &project.Resource{
+		Name: "app-image",
+		Type: registry.ResourceType(),
+		Source: &image.Source{
+			Registry:   registry,
+			Repository: "org/app",
+		},
+	}
+
+	pipeline := project.NewPipeline()
+	pipeline.Name = "repo-sdp"
+
+	args := pruneImagesArgs(registry, app)
+	args.ResourceRegistry = pipeline.ResourceRegistry
+	job, err := PruneImagesJob(args)
+	require.NoError(t, err)
+	pipeline.Jobs = project.Jobs{job}
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	rendered := yml.String()
+
+	assert.Contains(t, rendered, "- name: prune-images-timer\n  type: time\n  source:\n    interval: 24h\n")
+	assert.Contains(t, rendered, "get: prune-images-timer\n      trigger: true")
+	assert.Contains(t, rendered, "task: prune-app-image")
+	assert.Contains(t, rendered, "REPOSITORY: org/app")
+	assert.Contains(t, rendered, "TAG_PREFIX: installation-team-")
+	assert.Contains(t, rendered, "REGION: eu-west-1")
+	assert.Contains(t, rendered, "PIPELINE_REGEX: .*-sdpb$")
+	assert.Contains(t, rendered, "DRY_RUN: \"true\"")
+	assert.Contains(t, rendered, "path: /bin/aws/prune-scoped-ecr-images.sh")
+}
+
+func TestPruneImagesJobErrors(t *testing.T) {
+	public := &project.Resource{
+		Name: "public-image",
+		Type: image.DockerHub.ResourceType(),
+		Source: &image.Source{
+			Registry:   image.DockerHub,
+			Repository: "app",
+		},
+	}
+
+	_, err := PruneImagesJob(pruneImagesArgs(image.DockerHub, public))
+	assert.EqualError(t, err, "The pruned image public-image is not in an ECR registry")
+
+	registry := &image.Registry{
+		Domain: "123.dkr.ecr.eu-west-1.amazonaws.com",
+		Credentials: &image.EcrCredentials{
+			AccessKeyId:     "key",
+			SecretAccessKey: "secret",
+			RoleArn:         "arn:aws:iam::123:role/pull",
+		},
+	}
+
+	role := &project.Resource{
+		Name: "role-image",
+		Type: registry.ResourceType(),
+		Source: &image.Source{
+			Registry:   registry,
+			Repository: "org/app",
+		},
+	}
+
+	_, err = PruneImagesJob(pruneImagesArgs(registry, role))
+	assert.EqualError(t, err, "The pruned image role-image is accessed with a role, it is not supported")
+
+	args := pruneImagesArgs(image.DockerHub)
+	args.PipelineRegex = ""
+	_, err = PruneImagesJob(args)
+	assert.EqualError(t, err, "The pruned pipelines need a PipelineRegex")
+}
@@ -67,6 +67,22 @@ func (gb *GitBranch) IsTask() bool {
 	return isTaskPattern.MatchString(gb.Name) && !isPrPattern.MatchString(gb.Name)
 }
 
+func (gb *GitBranch) IsPr() bool {
+	return isPrPattern.MatchString(gb.Name)
+}
+
+// The branch the pull requests verified by the pull request branch are into, nil if it is not a pull request branch
+func (gb *GitBranch) PrTarget() *GitBranch {
+	matches := isPrPattern.FindAllStringSubmatch(gb.Name, -1)
+	if matches == nil {
+		return nil
+	}
+
+	return &GitBranch{
+		Name: matches[0][1],
+	}
+}
+
 func (gb *GitBranch) IsImage() bool {
 	return isImagePattern.MatchString(gb.Name)
 }
@@ -51,3 +51,21 @@ func TestGitBranch_TaskBranch(t *testing.T) {
 	prBranch = branch.PrBranch()
 	assert.False(t, prBranch.IsTask())
 }
+
+func TestGitBranch_PrBranch(t *testing.T) {
+	branch := &GitBranch{
+		Name: "release/1.0",
+	}
+	assert.False(t, branch.IsPr())
+	assert.Nil(t, branch.PrTarget())
+
+	prBranch := branch.PrBranch()
+	assert.True(t, prBranch.IsPr())
+	assert.Equal(t, "release/1.0", prBranch.PrTarget().Name)
+	assert.Equal(t, "release/1.0-pr", prBranch.FriendlyName())
+
+	prBranch = (&GitBranch{Name: "feature/foo#release/1.0"}).PrBranch()
+	assert.True(t, prBranch.IsPr())
+	assert.Equal(t, "feature/foo", prBranch.PrTarget().Name)
+	assert.Equal(t, "release/1.0", prBranch.BaseBranch().Name)
+}
@@ -10,7 +10,10 @@ type GitRepo struct {
 	PrivateKey string
 }
 
-var githubURL = regexp.MustCompile(`^git@github.com:.*?\/(.*?).git$`)
+var (
+	githubURL     = regexp.MustCompile(`^git@github.com:.*?\/(.*?).git$`)
+	githubRepoURL = regexp.MustCompile(`^git@github.com:(.*?\/.*?).git$`)
+)
 
 func (gr *GitRepo) FriendlyName() string {
 	if match := githubURL.FindAllStringSubmatch(gr.URI, -1); match != nil {
@@ -20,3 +23,11 @@ func (gr *GitRepo) FriendlyName() string {
 	// We do not know what this is, return it as is
 	return gr.URI
 }
+
+// The owner/name of a github repo, empty if the repo is not on github
+func (gr *GitRepo) GithubRepo() string {
+	if match := githubRepoURL.FindAllStringSubmatch(gr.URI, -1); match != nil {
+		return match[0][1]
+	}
+	return ""
+}
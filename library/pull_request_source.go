@@ -0,0 +1,72 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+)
+
+type PullRequestStatus string
+
+const (
+	PullRequestPending PullRequestStatus = "pending"
+	PullRequestSuccess PullRequestStatus = "success"
+	PullRequestFailure PullRequestStatus = "failure"
+)
+
+type PullRequestSource struct {
+	// Github repo and credentials
+	Repo *primitive.GitRepo
+
+	// The branch the pull requests are into
+	Base *primitive.GitBranch
+
+	// Github access token the pull requests are read and their statuses updated with
+	AccessToken string
+}
+
+func (prs *PullRequestSource) Validate() error {
+	if prs.Repo.GithubRepo() == "" {
+		return fmt.Errorf("Pull requests are supported only for github repos, %s is not", prs.Repo.URI)
+	}
+
+	if prs.AccessToken == "" {
+		return fmt.Errorf("Pull requests of %s need an access token", prs.Repo.URI)
+	}
+	return nil
+}
+
+func (prs *PullRequestSource) ModelSource(scope project.Scope, info *project.ScopeInfo) interface{} {
+	return &resource.GitPullRequestSource{
+		Repo:        prs.Repo.GithubRepo(),
+		URI:         prs.Repo.URI,
+		PrivateKey:  prs.Repo.PrivateKey,
+		Base:        prs.Base.CanonicalName(),
+		AccessToken: prs.AccessToken,
+	}
+}
+
+// Updates the status of the commit of a pull request
+type PullRequestPutParams struct {
+	// The fetched pull request
+	PullRequest *project.JobResource
+
+	Status PullRequestStatus
+
+	// The context of the status, e.g. the name of the job that verifies the pull request
+	Context string
+}
+
+func (prpp *PullRequestPutParams) ModelParams() interface{} {
+	return &resource.GitPullRequestPutParams{
+		Path:    prpp.PullRequest.Path(),
+		Status:  string(prpp.Status),
+		Context: prpp.Context,
+	}
+}
+
+func (prpp *PullRequestPutParams) InputResources() project.JobResources {
+	return project.JobResources{prpp.PullRequest}
+}
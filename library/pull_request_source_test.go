@@ -0,0 +1,86 @@
+package library
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestStatuses(t *testing.T) {
+	pipeline := project.NewPipeline()
+	pipeline.Name = "master-pr-sdpb"
+
+	pullRequestResource := &project.Resource{
+		Name: "master-pr",
+		Type: resource.PullRequestResourceType.Name,
+		Source: &PullRequestSource{
+			Repo:        &primitive.GitRepo{URI: "git@github.com:org/repo.git"},
+			Base:        &primitive.GitBranch{Name: "master"},
+			AccessToken: "token",
+		},
+	}
+
+	pullRequest := pipeline.ResourceRegistry.JobResource(pullRequestResource, true, nil)
+	pullRequest.Version = "every"
+
+	status := func(status PullRequestStatus) project.IStep {
+		return &project.PutStep{
+			Resource: pullRequestResource,
+			Params: &PullRequestPutParams{
+				PullRequest: pullRequest,
+				Status:      status,
+				Context:     "test",
+			},
+		}
+	}
+
+	job := &project.Job{
+		Name: "test",
+		Steps: project.ISteps{
+			status(PullRequestPending),
+			&project.TaskStep{
+				Platform: model.LinuxPlatform,
+				Name:     "test",
+				Image:    pipeline.ResourceRegistry.JobResource(image.Ubuntu, true, nil),
+				Run:      &dummyRun{},
+			},
+		},
+	}
+	job.AddOnSuccess(status(PullRequestSuccess))
+	job.AddOnFailure(status(PullRequestFailure))
+	pipeline.Jobs = project.Jobs{job}
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	rendered := yml.String()
+
+	assert.Contains(t, rendered, "  type: pull-request\n  source:\n    repo: org/repo\n"+
+		"    uri: git@github.com:org/repo.git\n    base: master\n    access_token: token\n")
+	assert.Contains(t, rendered, "- get: master-pr\n      trigger: true\n      version: every\n")
+	assert.Contains(t, rendered, "  - put: master-pr\n    params:\n      path: master-pr\n      status: pending\n"+
+		"      context: test\n")
+	assert.Contains(t, rendered, "  on_success:\n    put: master-pr\n    params:\n      path: master-pr\n"+
+		"      status: success\n")
+	assert.Contains(t, rendered, "status: failure")
+}
+
+func TestPullRequestSourceValidate(t *testing.T) {
+	source := &PullRequestSource{
+		Repo:        &primitive.GitRepo{URI: "https://gitlab.com/org/repo.git"},
+		Base:        &primitive.GitBranch{Name: "master"},
+		AccessToken: "token",
+	}
+	assert.EqualError(t, source.Validate(),
+		"Pull requests are supported only for github repos, https://gitlab.com/org/repo.git is not")
+
+	source.Repo.URI = "git@github.com:org/repo.git"
+	source.AccessToken = ""
+	assert.EqualError(t, source.Validate(), "Pull requests of git@github.com:org/repo.git need an access token")
+}
@@ -13,9 +13,17 @@ func (ris *ResourceImageSource) ResourceScope() project.Scope {
 }
 
 func (ris *ResourceImageSource) ModelSource(scope project.Scope, info *project.ScopeInfo) interface{} {
-	return ris.Source.ModelSource(scope, info)
+	return ris.Source.ModelSource(scope, info.WithNaming(ris.Naming))
 }
 
 func (ris *ResourceImageSource) NeededJobs() project.Jobs {
 	return (*project.Resource)(ris).NeededJobs()
 }
+
+// Validates the source of the image, the credentials of its registry for example
+func (ris *ResourceImageSource) Validate() error {
+	if validator, ok := ris.Source.(project.ISourceValidator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package library
+
+import (
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceImageSourceValidate(t *testing.T) {
+	registry := &image.Registry{
+		Domain:      "registry.example.com",
+		Credentials: &image.UsernamePassword{Username: "user"},
+	}
+
+	source := &ResourceImageSource{
+		Name: "resource-image",
+		Type: registry.ResourceType(),
+		Source: &image.Source{
+			Registry:   registry,
+			Repository: "resource",
+		},
+	}
+
+	resourceType := &project.ResourceType{
+		Name:   "resource",
+		Type:   "docker-image",
+		Source: source,
+	}
+
+	_, err := resourceType.Model(&project.ScopeInfo{Team: "team"})
+	assert.EqualError(t, err, "Resource type resource: Registry registry.example.com: "+
+		"Username and Password make sense only as pair")
+
+	registry.Credentials = &image.UsernamePassword{Username: "user", Password: "password"}
+	assert.NoError(t, source.Validate())
+}
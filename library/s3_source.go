@@ -15,13 +15,11 @@ type S3Source struct {
 }
 
 func (s3s *S3Source) ModelSource(scope project.Scope, info *project.ScopeInfo) interface{} {
-	filePrefix := info.Scope(scope, "_")
-
 	return &resource.S3Source{
 		Bucket:          s3s.Bucker.Name,
 		AccessKeyID:     s3s.Bucker.AccessKeyID,
 		SecretAccessKey: s3s.Bucker.SecretAccessKey,
 		RegionName:      s3s.Bucker.RegionName,
-		VersionedFile:   filePrefix + s3s.VersionedFile,
+		VersionedFile:   info.Name(scope, "_", s3s.VersionedFile),
 	}
 }
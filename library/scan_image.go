@@ -0,0 +1,176 @@
+package library
+
+import (
+	"strings"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+var ScansGroup = &project.JobGroup{
+	Name: "scans",
+	After: project.JobGroups{
+		ImagesGroup,
+	},
+}
+
+// The severity of a vulnerability
+type Severity int
+
+const (
+	SeverityUnknown Severity = iota + 1
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityUnknown:
+		return "UNKNOWN"
+	case SeverityLow:
+		return "LOW"
+	case SeverityMedium:
+		return "MEDIUM"
+	case SeverityHigh:
+		return "HIGH"
+	case SeverityCritical:
+		return "CRITICAL"
+	}
+	return ""
+}
+
+// The severity and the higher ones
+func (s Severity) AndAbove() []Severity {
+	var severities []Severity
+	for severity := s; severity <= SeverityCritical; severity++ {
+		severities = append(severities, severity)
+	}
+	return severities
+}
+
+// A scanner of image vulnerabilities
+type IImageScanner interface {
+	// The image the scan task runs in
+	ScannerImage() *project.Resource
+
+	// The command that scans the image archive and fails on vulnerabilities of the severity or above
+	ScanCommand(archive project.IValue, failOn Severity) (project.IRun, []interface{})
+}
+
+// Scans with trivy
+type TrivyScanner struct {
+	// The trivy image, image.Trivy if nil
+	Image *project.Resource
+
+	// Additional arguments of trivy image
+	Arguments []string
+}
+
+func (ts *TrivyScanner) ScannerImage() *project.Resource {
+	if ts.Image != nil {
+		return ts.Image
+	}
+	return image.Trivy
+}
+
+func (ts *TrivyScanner) ScanCommand(archive project.IValue, failOn Severity) (project.IRun, []interface{}) {
+	var severities []string
+	for _, severity := range failOn.AndAbove() {
+		severities = append(severities, severity.String())
+	}
+
+	run := &primitive.Location{
+		Volume: &primitive.Directory{
+			Root: "/usr/local/bin",
+		},
+		RelativePath: "trivy",
+	}
+
+	arguments := []interface{}{
+		"image",
+		"--no-progress",
+		"--exit-code", "1",
+		"--severity", strings.Join(severities, ","),
+		"--input", archive,
+	}
+
+	for _, argument := range ts.Arguments {
+		arguments = append(arguments, argument)
+	}
+
+	return run, arguments
+}
+
+type ScanImageArgs struct {
+	ResourceRegistry *project.ResourceRegistry
+
+	// The scanned image, built by BuildImage for example
+	Image *project.Resource
+
+	// The scanner, trivy if nil
+	Scanner IImageScanner
+
+	// The scan fails on vulnerabilities of this severity or above, SeverityHigh if not set
+	FailOn Severity
+}
+
+// An image with the job that scans it
+type ScannedImage struct {
+	Image *project.Resource
+	Job   *project.Job
+}
+
+// The image gated by the scan, the consuming jobs get only the versions that passed the scan
+func (si *ScannedImage) JobResource(registry *project.ResourceRegistry, trigger bool,
+	getParams interface{}) *project.JobResource {
+
+	jobResource := registry.JobResource(si.Image, trigger, getParams)
+	jobResource.Passed = project.Jobs{si.Job}
+	return jobResource
+}
+
+// Generates the job that scans the image for vulnerabilities
+func ScanImageJob(args *ScanImageArgs) *ScannedImage {
+	scanner := args.Scanner
+	if scanner == nil {
+		scanner = &TrivyScanner{}
+	}
+
+	failOn := args.FailOn
+	if failOn == 0 {
+		failOn = SeverityHigh
+	}
+
+	getParams, archive := args.Image.Source.(*image.Source).Registry.ArchiveGetParams()
+	imageResource := args.ResourceRegistry.JobResource(args.Image, true, getParams)
+
+	taskScan := &project.TaskStep{
+		Platform: model.LinuxPlatform,
+		Name:     "scan",
+		Image:    args.ResourceRegistry.JobResource(scanner.ScannerImage(), true, nil),
+	}
+
+	taskScan.Run, taskScan.Arguments = scanner.ScanCommand(&primitive.Location{
+		Volume:       imageResource,
+		RelativePath: archive,
+	}, failOn)
+
+	job := &project.Job{
+		Name: project.JobName(string(args.Image.Name) + "-scan"),
+		Groups: project.JobGroups{
+			ScansGroup,
+		},
+		Steps: project.ISteps{
+			taskScan,
+		},
+	}
+
+	return &ScannedImage{
+		Image: args.Image,
+		Job:   job,
+	}
+}
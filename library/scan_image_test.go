@@ -0,0 +1,115 @@
+package library
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestSeverityAndAbove(t *testing.T) {
+	assert.Equal(t, []Severity{SeverityHigh, SeverityCritical}, SeverityHigh.AndAbove())
+	assert.Equal(t, []Severity{SeverityCritical}, SeverityCritical.AndAbove())
+	assert.Len(t, SeverityUnknown.AndAbove(), 5)
+}
+
+func TestScanImageJob(t *testing.T) {
+	pipeline := project.NewPipeline()
+	pipeline.Name = "images"
+
+	built := &project.Resource{
+		Name: "built-image",
+		Type: image.DockerHub.ResourceType(),
+		Source: &image.Source{
+			Registry:   image.DockerHub,
+			Repository: "built",
+		},
+	}
+	pipeline.ResourceRegistry.MustRegister(built)
+
+	dockerfile := &image.Dockerfile{}
+	dockerfile.Final().User("root")
+
+	build := MustBuildImage(&BuildImageArgs{
+		ResourceRegistry: pipeline.ResourceRegistry,
+		PrepareImage:     image.Ubuntu,
+		From:             image.Alpine,
+		Name:             "built",
+		Dockerfile:       dockerfile,
+		Image:            built,
+	})
+	built.NeedJobs(build)
+
+	scanned := ScanImageJob(&ScanImageArgs{
+		ResourceRegistry: pipeline.ResourceRegistry,
+		Image:            built,
+		Scanner: &TrivyScanner{
+			Arguments: []string{"--ignore-unfixed"},
+		},
+		FailOn: SeverityCritical,
+	})
+
+	pipeline.Jobs = project.Jobs{
+		&project.Job{
+			Name: "deploy",
+			Steps: project.ISteps{
+				&project.TaskStep{
+					Platform: "linux",
+					Name:     "deploy",
+					Image:    scanned.JobResource(pipeline.ResourceRegistry, true, nil),
+					Run:      &dummyRun{},
+				},
+			},
+		},
+	}
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+
+	rendered := struct {
+		Groups []struct {
+			Name string
+			Jobs []string
+		}
+		Jobs []struct {
+			Name string
+			Plan []map[string]interface{}
+		}
+	}{}
+	require.NoError(t, yaml.Unmarshal(yml.Bytes(), &rendered))
+
+	groups := map[string][]string{}
+	var groupNames []string
+	for _, group := range rendered.Groups {
+		groups[group.Name] = group.Jobs
+		groupNames = append(groupNames, group.Name)
+	}
+	assert.Equal(t, []string{"built-image-scan"}, groups["scans"])
+
+	// The scans are shown after the images they scan
+	assert.Equal(t, []string{"images", "scans"}, groupNames)
+
+	plans := map[string][]map[string]interface{}{}
+	for _, job := range rendered.Jobs {
+		plans[job.Name] = job.Plan
+	}
+
+	require.Contains(t, plans, "deploy")
+	assert.Equal(t, "built-image", plans["deploy"][0]["get"])
+	assert.Equal(t, []interface{}{"built-image-scan"}, plans["deploy"][0]["passed"])
+
+	require.Contains(t, plans, "built-image-scan")
+	assert.Contains(t, yml.String(), "- --severity\n        - CRITICAL\n        - --input\n        - built-image/image\n"+
+		"        - --ignore-unfixed\n")
+	assert.Contains(t, yml.String(), "save: true")
+}
+
+type dummyRun struct{}
+
+func (dr *dummyRun) Path() string {
+	return "true"
+}
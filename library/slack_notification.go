@@ -0,0 +1,132 @@
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+)
+
+// The metadata of a build, as environment variables the slack resource expands in the messages
+type BuildMetadata struct {
+	Id           string
+	Name         string
+	JobName      string
+	PipelineName string
+	TeamName     string
+	ExternalUrl  string
+}
+
+// The URL of the page of the build
+func (bm *BuildMetadata) Url() string {
+	return fmt.Sprintf("%s/teams/%s/pipelines/%s/jobs/%s/builds/%s",
+		bm.ExternalUrl, bm.TeamName, bm.PipelineName, bm.JobName, bm.Name)
+}
+
+// The metadata the slack notification texts are executed with
+var SlackBuildMetadata = &BuildMetadata{
+	Id:           "$BUILD_ID",
+	Name:         "$BUILD_NAME",
+	JobName:      "$BUILD_JOB_NAME",
+	PipelineName: "$BUILD_PIPELINE_NAME",
+	TeamName:     "$BUILD_TEAM_NAME",
+	ExternalUrl:  "$ATC_EXTERNAL_URL",
+}
+
+const (
+	DefaultFailureText = "{{.PipelineName}}/{{.JobName}} build {{.Name}} failed: {{.Url}}"
+	DefaultSuccessText = "{{.PipelineName}}/{{.JobName}} build {{.Name}} succeeded: {{.Url}}"
+)
+
+type SlackNotification struct {
+	// The slack-notification resource the notification is put to
+	Slack *project.Resource
+
+	// Template of the message executed with the BuildMetadata, e.g. "{{.JobName}} failed: {{.Url}}"
+	Text string
+
+	// Optional. Overrides the channel of the webhook
+	Channel string
+
+	// Optional. Overrides the name and the icon of the webhook bot
+	Username  string
+	IconEmoji string
+}
+
+// The message of the notification with the build metadata
+func (sn *SlackNotification) Message() (string, error) {
+	text, err := template.New("slack").Option("missingkey=error").Parse(sn.Text)
+	if err != nil {
+		return "", fmt.Errorf("Slack notification text %q is invalid: %s", sn.Text, err.Error())
+	}
+
+	message := &bytes.Buffer{}
+	err = text.Execute(message, SlackBuildMetadata)
+	if err != nil {
+		return "", fmt.Errorf("Slack notification text %q is invalid: %s", sn.Text, err.Error())
+	}
+
+	return message.String(), nil
+}
+
+// The put step of the notification, the slack resource is registered.
+// The text is executed when the step is created, so an invalid text fails before the render.
+func (sn *SlackNotification) step(resourceRegistry *project.ResourceRegistry, defaultText string) (project.IStep, error) {
+	notification := *sn
+	if notification.Text == "" {
+		notification.Text = defaultText
+	}
+
+	message, err := notification.Message()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceRegistry.MustRegister(sn.Slack)
+
+	return &project.PutStep{
+		Resource: sn.Slack,
+		Params: &resource.SlackPutParams{
+			Text:      message,
+			Channel:   sn.Channel,
+			Username:  sn.Username,
+			IconEmoji: sn.IconEmoji,
+		},
+	}, nil
+}
+
+// Notifies when a build of the job fails, DefaultFailureText is the text if the notification has none
+func NotifyOnFailure(resourceRegistry *project.ResourceRegistry, job *project.Job, notification *SlackNotification) error {
+	step, err := notification.step(resourceRegistry, DefaultFailureText)
+	if err != nil {
+		return err
+	}
+
+	job.AddOnFailure(step)
+	return nil
+}
+
+// Notifies when a build of the job succeeds, DefaultSuccessText is the text if the notification has none
+func NotifyOnSuccess(resourceRegistry *project.ResourceRegistry, job *project.Job, notification *SlackNotification) error {
+	step, err := notification.step(resourceRegistry, DefaultSuccessText)
+	if err != nil {
+		return err
+	}
+
+	job.AddOnSuccess(step)
+	return nil
+}
+
+// Notifies when a build of any job of the pipeline fails, including the jobs the pipeline jobs need.
+// The notification is added when the pipeline is rendered, the jobs shared with other pipelines are not changed.
+func NotifyPipelineFailures(pipeline *project.Pipeline, notification *SlackNotification) error {
+	step, err := notification.step(pipeline.ResourceRegistry, DefaultFailureText)
+	if err != nil {
+		return err
+	}
+
+	pipeline.AddOnFailure(step)
+	return nil
+}
@@ -0,0 +1,99 @@
+package library
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackNotificationMessage(t *testing.T) {
+	notification := &SlackNotification{
+		Text: "{{.JobName}} #{{.Name}} failed, see {{.Url}}",
+	}
+
+	message, err := notification.Message()
+	require.NoError(t, err)
+	assert.Equal(t, "$BUILD_JOB_NAME #$BUILD_NAME failed, see $ATC_EXTERNAL_URL/teams/$BUILD_TEAM_NAME/"+
+		"pipelines/$BUILD_PIPELINE_NAME/jobs/$BUILD_JOB_NAME/builds/$BUILD_NAME", message)
+
+	notification.Text = "{{.Branch}}"
+	_, err = notification.Message()
+	assert.Error(t, err)
+}
+
+func TestNotifyOnFailure(t *testing.T) {
+	pipeline := project.NewPipeline()
+	pipeline.Name = "notify"
+
+	slack := &project.Resource{
+		Name:   "slack",
+		Type:   resource.SlackResourceType.Name,
+		Source: &resource.SlackSource{URL: "https://hooks.slack.com/services/secret"},
+	}
+
+	newJob := func(name project.JobName) *project.Job {
+		return &project.Job{
+			Name: name,
+			Steps: project.ISteps{
+				&project.TaskStep{
+					Platform: model.LinuxPlatform,
+					Name:     "test",
+					Image:    pipeline.ResourceRegistry.JobResource(image.Ubuntu, true, nil),
+					Run:      &dummyRun{},
+				},
+			},
+		}
+	}
+
+	build := newJob("build")
+	deploy := newJob("deploy")
+	deploy.AddJobToRunAfter(build)
+	pipeline.Jobs = project.Jobs{build, deploy}
+
+	require.NoError(t, NotifyOnSuccess(pipeline.ResourceRegistry, deploy,
+		&SlackNotification{Slack: slack, Channel: "#deploys"}))
+	require.NoError(t, NotifyPipelineFailures(pipeline, &SlackNotification{Slack: slack}))
+
+	// Notifying twice does not duplicate the notification
+	require.NoError(t, NotifyPipelineFailures(pipeline, &SlackNotification{Slack: slack}))
+	require.IsType(t, &project.PutStep{}, pipeline.OnFailure)
+
+	// The pipeline notification is added at render, the jobs are not changed
+	assert.Nil(t, build.OnFailure)
+
+	// Both hooks of a job run
+	require.NoError(t, NotifyOnFailure(pipeline.ResourceRegistry, deploy,
+		&SlackNotification{Slack: slack, Text: "deploy failed"}))
+	require.IsType(t, &project.PutStep{}, deploy.OnFailure)
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	rendered := yml.String()
+
+	assert.Contains(t, rendered, "- name: slack\n  type: slack-notification\n  source:\n"+
+		"    url: https://hooks.slack.com/services/secret\n")
+	assert.Contains(t, rendered, "  on_success:\n    put: slack\n    params:\n"+
+		"      text: '$BUILD_PIPELINE_NAME/$BUILD_JOB_NAME build $BUILD_NAME succeeded:")
+	assert.Contains(t, rendered, "      channel: '#deploys'\n")
+	assert.Contains(t, rendered, "- name: build\n  plan:\n")
+	assert.Contains(t, rendered, "  on_failure:\n    put: slack\n    params:\n"+
+		"      text: '$BUILD_PIPELINE_NAME/$BUILD_JOB_NAME build $BUILD_NAME failed:")
+	assert.Contains(t, rendered, "  on_failure:\n    do:\n    - put: slack\n      params:\n"+
+		"        text: deploy failed\n    - put: slack\n")
+	assert.Equal(t, 2, strings.Count(rendered, "on_failure:"))
+
+	// An invalid text fails before the notification is added
+	invalid := &SlackNotification{Slack: slack, Text: "{{.Url"}
+	assert.Error(t, NotifyOnFailure(pipeline.ResourceRegistry, build, invalid))
+	assert.Error(t, NotifyOnSuccess(pipeline.ResourceRegistry, build, invalid))
+	assert.Error(t, NotifyPipelineFailures(pipeline, invalid))
+	assert.Nil(t, build.OnFailure)
+	assert.Nil(t, build.OnSuccess)
+}
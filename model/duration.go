@@ -7,7 +7,14 @@ import (
 
 type Duration time.Duration
 
+// The check interval of the resources concourse never checks
+const NeverDuration = Duration(-1)
+
 func (d Duration) MarshalYAML() (interface{}, error) {
+	if d == NeverDuration {
+		return "never", nil
+	}
+
 	str := time.Duration(d).String()
 	if strings.HasSuffix(str, "h0m0s") {
 		return str[:len(str)-4], nil
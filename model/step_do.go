@@ -10,5 +10,5 @@ type Do struct {
 	Do ISteps
 
 	// Time duration in which the execution of the do steps will be timed-out
-	Timeout time.Duration
+	Timeout time.Duration `yaml:",omitempty"`
 }
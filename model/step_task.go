@@ -120,4 +120,7 @@ type Task struct {
 
 	// A number of attempts before the task is considered to fail
 	Attempts int `yaml:",omitempty"`
+
+	// Only workers with all the tags run the task
+	Tags []string `yaml:",omitempty"`
 }
@@ -0,0 +1,187 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// One edge of an ordering cycle: From <Relation> To
+type CycleLink struct {
+	From     string
+	Relation string
+	To       string
+
+	// Why the edge exists, empty if unknown
+	Reason string
+}
+
+func (cl *CycleLink) String() string {
+	link := fmt.Sprintf("%s %s %s", cl.From, cl.Relation, cl.To)
+	if cl.Reason != "" {
+		link += fmt.Sprintf(" (%s)", cl.Reason)
+	}
+	return link
+}
+
+// Returned when jobs or groups can not be ordered because they form a cycle
+type CycleError struct {
+	// What is ordered, jobs or groups
+	Kind string
+
+	// The edges of the cycle, the last one leads back to the first one
+	Links []*CycleLink
+}
+
+func (ce *CycleError) Error() string {
+	links := make([]string, 0, len(ce.Links))
+	for _, link := range ce.Links {
+		links = append(links, link.String())
+	}
+	return fmt.Sprintf("There are %s in circular positionaning: %s", ce.Kind, strings.Join(links, ", "))
+}
+
+// Describes the code that called the function calling callerReason
+func callerReason() string {
+	_, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return "AddJobToRunAfter"
+	}
+	return fmt.Sprintf("AddJobToRunAfter at %s:%d", filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file)), line)
+}
+
+// Finds a cycle with a depth first search over the nodes 0..count-1. The cycle is what the stack holds
+// from the node a back edge leads to, so only the nodes of the cycle are in it. Each node leads to
+// the next one and the last one to the first one. Nil if there is no cycle.
+func findCycle(count int, next func(node int) []int) []int {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+
+	state := make([]int, count)
+	var stack []int
+
+	var visit func(node int) []int
+	visit = func(node int) []int {
+		state[node] = onStack
+		stack = append(stack, node)
+
+		for _, nextNode := range next(node) {
+			switch state[nextNode] {
+			case onStack:
+				for i, stackNode := range stack {
+					if stackNode == nextNode {
+						return append([]int(nil), stack[i:]...)
+					}
+				}
+			case unvisited:
+				if cycle := visit(nextNode); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[node] = done
+		return nil
+	}
+
+	for node := 0; node < count; node++ {
+		if state[node] != unvisited {
+			continue
+		}
+		if cycle := visit(node); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// Finds a cycle among jobs that are all blocked by another blocked job
+func jobsCycle(blocked map[*Job]struct{}, order jobOrder) *CycleError {
+	var jobs Jobs
+	for job := range blocked {
+		jobs = append(jobs, job)
+	}
+	sort.Sort(jobs)
+
+	indexes := make(map[*Job]int, len(jobs))
+	for i, job := range jobs {
+		indexes[job] = i
+	}
+
+	path := findCycle(len(jobs), func(node int) []int {
+		var afterJobs Jobs
+		for afterJob := range order[jobs[node]] {
+			if _, ok := blocked[afterJob]; ok {
+				afterJobs = append(afterJobs, afterJob)
+			}
+		}
+		sort.Sort(afterJobs)
+
+		var next []int
+		for _, afterJob := range afterJobs {
+			next = append(next, indexes[afterJob])
+		}
+		return next
+	})
+
+	cycle := &CycleError{
+		Kind: "jobs",
+	}
+	for i, node := range path {
+		job := jobs[node]
+		afterJob := jobs[path[(i+1)%len(path)]]
+		cycle.Links = append(cycle.Links, &CycleLink{
+			From:     string(job.Name),
+			Relation: "runs after",
+			To:       string(afterJob.Name),
+			Reason:   order[job][afterJob],
+		})
+	}
+
+	return cycle
+}
+
+// Finds a cycle among groups that are all blocked by another blocked group
+func groupsCycle(blocked map[*JobGroup]struct{}, relation string, related func(group *JobGroup) JobGroups) *CycleError {
+	var groups JobGroups
+	for group := range blocked {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+
+	indexes := make(map[*JobGroup]int, len(groups))
+	for i, group := range groups {
+		indexes[group] = i
+	}
+
+	path := findCycle(len(groups), func(node int) []int {
+		var next []int
+		for _, relatedGroup := range related(groups[node]) {
+			if i, ok := indexes[relatedGroup]; ok {
+				next = append(next, i)
+			}
+		}
+		return next
+	})
+
+	cycle := &CycleError{
+		Kind: "groups",
+	}
+	for i, node := range path {
+		cycle.Links = append(cycle.Links, &CycleLink{
+			From:     groups[node].Name,
+			Relation: relation,
+			To:       groups[path[(i+1)%len(path)]].Name,
+		})
+	}
+
+	return cycle
+}
@@ -0,0 +1,122 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobsCycleReportsReasons(t *testing.T) {
+	pipeline := NewPipeline()
+
+	image := &Resource{Name: "image", Type: "graph-test", Source: &testSource{Id: "cycle-image"}}
+
+	build := &Job{
+		Name: "build",
+		Steps: ISteps{
+			&testStep{output: image},
+		},
+	}
+	image.NeedJobs(build)
+
+	test := &Job{
+		Name: "test",
+		Steps: ISteps{
+			&testStep{
+				inputs: JobResources{
+					pipeline.ResourceRegistry.JobResource(image, true, nil),
+				},
+			},
+		},
+	}
+	build.AddJobToRunAfter(test)
+
+	pipeline.Jobs = Jobs{build, test}
+
+	index, err := pipeline.resourceIndex()
+	require.NoError(t, err)
+
+	_, err = index.SortByColumns()
+	require.Error(t, err)
+
+	cycle, ok := err.(*CycleError)
+	require.True(t, ok)
+	require.Len(t, cycle.Links, 2)
+
+	assert.Equal(t, "build", cycle.Links[0].From)
+	assert.Equal(t, "test", cycle.Links[0].To)
+	assert.Contains(t, cycle.Links[0].Reason, "AddJobToRunAfter at project/cycle_test.go:")
+
+	assert.Equal(t, "test runs after build (needs resource image)", cycle.Links[1].String())
+	assert.Contains(t, err.Error(), "There are jobs in circular positionaning: build runs after test (")
+}
+
+func TestGroupsCycleReportsPath(t *testing.T) {
+	a := &JobGroup{
+		Name: "A",
+	}
+	b := &JobGroup{
+		Name:  "B",
+		After: JobGroups{a},
+	}
+	c := &JobGroup{
+		Name:  "C",
+		After: JobGroups{b},
+	}
+	a.After = JobGroups{c}
+
+	_, err := SortJobGroups(JobGroups{a, b, c})
+	assert.EqualError(t, err, "There are groups in circular positionaning: A is after C, C is after B, B is after A")
+
+	d := &JobGroup{
+		Name: "D",
+	}
+	d.Before = JobGroups{d}
+
+	_, err = SortJobGroups(JobGroups{d})
+	assert.EqualError(t, err, "There are groups in circular positionaning: D is before D")
+}
+
+func TestGroupsCycleReportsOnlyTheCycle(t *testing.T) {
+	e := &JobGroup{
+		Name: "E",
+	}
+	d := &JobGroup{
+		Name:  "D",
+		After: JobGroups{e},
+	}
+	e.After = JobGroups{d}
+
+	// A diamond leading to the cycle, its groups are blocked but not part of the cycle
+	b := &JobGroup{
+		Name:  "B",
+		After: JobGroups{d},
+	}
+	c := &JobGroup{
+		Name:  "C",
+		After: JobGroups{d},
+	}
+	a := &JobGroup{
+		Name:  "A",
+		After: JobGroups{b, c},
+	}
+
+	_, err := SortJobGroups(JobGroups{a, b, c, d, e})
+	assert.EqualError(t, err, "There are groups in circular positionaning: D is after E, E is after D")
+
+	// Only a back edge closes the reported path, the blocked groups without a blocked group after them
+	// do not lead back to where the path started
+	leaf := &JobGroup{
+		Name: "B",
+	}
+	blockedByLeaf := &JobGroup{
+		Name:  "A",
+		After: JobGroups{leaf},
+	}
+	cycle := groupsCycle(map[*JobGroup]struct{}{blockedByLeaf: {}, leaf: {}, d: {}, e: {}}, "is after",
+		func(group *JobGroup) JobGroups {
+			return group.After
+		})
+	assert.EqualError(t, cycle, "There are groups in circular positionaning: D is after E, E is after D")
+}
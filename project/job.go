@@ -2,6 +2,7 @@ package project
 
 import (
 	"log"
+	"reflect"
 
 	"github.com/concourse-friends/concourse-builder/model"
 )
@@ -17,21 +18,82 @@ type Job struct {
 	OnSuccess      IStep
 	OnFailure      IStep
 	AfterJobs      map[*Job]struct{}
+
+	// Why the job runs after each of the after jobs
+	afterReasons map[*Job]string
 }
 
 func (job *Job) AddToGroup(groups ...*JobGroup) {
 	job.Groups = append(job.Groups, groups...)
 }
 
+// Adds a step to run when the job succeeds, after the steps added before
+func (job *Job) AddOnSuccess(step IStep) {
+	job.OnSuccess = appendHookStep(job.OnSuccess, step)
+}
+
+// Adds a step to run when the job fails, after the steps added before
+func (job *Job) AddOnFailure(step IStep) {
+	job.OnFailure = appendHookStep(job.OnFailure, step)
+}
+
+// The hook with the step appended. The hook is not changed, it might be shared by other jobs.
+// A step equal to one of the hook is not appended again.
+func appendHookStep(hook IStep, step IStep) IStep {
+	switch hook := hook.(type) {
+	case nil:
+		return step
+	case *DoStep:
+		for _, hookStep := range hook.Steps {
+			if reflect.DeepEqual(hookStep, step) {
+				return hook
+			}
+		}
+
+		steps := make(ISteps, 0, len(hook.Steps)+1)
+		steps = append(steps, hook.Steps...)
+		return &DoStep{
+			Steps: append(steps, step),
+		}
+	default:
+		if reflect.DeepEqual(hook, step) {
+			return hook
+		}
+
+		return &DoStep{
+			Steps: ISteps{hook, step},
+		}
+	}
+}
+
+// A copy of the job with the failure hook of the pipeline, it collects the resources of the render
+func (job *Job) withPipelineHook(onFailure IStep) *Job {
+	if onFailure == nil {
+		return job
+	}
+
+	hooked := *job
+	hooked.OnFailure = appendHookStep(job.OnFailure, onFailure)
+	return &hooked
+}
+
 func (job *Job) AddJobToRunAfter(jobs ...*Job) {
+	job.addJobToRunAfter(callerReason(), jobs...)
+}
+
+func (job *Job) addJobToRunAfter(reason string, jobs ...*Job) {
 	if job.AfterJobs == nil {
 		job.AfterJobs = make(map[*Job]struct{})
 	}
+	if job.afterReasons == nil {
+		job.afterReasons = make(map[*Job]string)
+	}
 
 	for _, afterJob := range jobs {
 		if _, ok := job.AfterJobs[afterJob]; !ok {
-			log.Printf("Run job %s after %s", job.Name, afterJob.Name)
+			log.Printf("Run job %s after %s: %s", job.Name, afterJob.Name, reason)
 			job.AfterJobs[afterJob] = struct{}{}
+			job.afterReasons[afterJob] = reason
 		}
 	}
 }
@@ -70,11 +132,11 @@ func (job *Job) Resources() (JobResources, error) {
 		}
 		resources = append(resources, inputResources...)
 
-		outputResource, err := step.OutputResource()
+		outputResources, err := stepOutputResources(step)
 		if err != nil {
 			return nil, err
 		}
-		if outputResource != nil {
+		for _, outputResource := range outputResources {
 			resources = append(resources, &JobResource{Name: outputResource.Name})
 		}
 	}
@@ -82,34 +144,41 @@ func (job *Job) Resources() (JobResources, error) {
 	return resources.Deduplicate(), nil
 }
 
-func (job *Job) Model(previousColumns []Jobs) (*model.Job, error) {
+func (job *Job) OutputResources() (JobResources, error) {
+	var resources JobResources
+
+	steps := append(ISteps{job.OnSuccess, job.OnFailure}, job.Steps...)
+	for _, step := range steps {
+		if step == nil {
+			continue
+		}
+		outputResources, err := stepOutputResources(step)
+		if err != nil {
+			return nil, err
+		}
+		for _, outputResource := range outputResources {
+			resources = append(resources, &JobResource{Name: outputResource.Name})
+		}
+	}
+
+	return resources.Deduplicate(), nil
+}
+
+func (job *Job) Model(index *ResourceIndex) (*model.Job, error) {
 	var err error
 
 	var modelSteps model.ISteps
 
-	inputs, err := job.InputResources()
-	if err != nil {
-		return nil, err
-	}
-
 	var modelGetSteps model.ISteps
-	for _, input := range inputs {
+	for _, input := range index.Inputs(job) {
 		step := &model.Get{
 			Get:     model.ResourceName(input.Name),
 			Trigger: input.Trigger,
+			Version: input.Version,
 			Params:  input.GetParams,
 		}
 
-		for c := len(previousColumns) - 1; c >= 0; c-- {
-			step.Passed, err = previousColumns[c].NamesOfUsingResourceJobs(input)
-			if err != nil {
-				return nil, err
-			}
-
-			if step.Passed != nil {
-				break
-			}
-		}
+		step.Passed, _ = passedJobs(job, index, input)
 
 		modelGetSteps = append(modelGetSteps, step)
 	}
@@ -128,7 +197,10 @@ func (job *Job) Model(previousColumns []Jobs) (*model.Job, error) {
 		if err != nil {
 			return nil, err
 		}
-		modelSteps = append(modelSteps, modelStep)
+		if err := resolveScopedValues(modelStep, index.info); err != nil {
+			return nil, err
+		}
+		modelSteps = append(modelSteps, index.canonicalPut(modelStep))
 	}
 
 	var modelOnSuccessStep model.IStep
@@ -137,14 +209,27 @@ func (job *Job) Model(previousColumns []Jobs) (*model.Job, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := resolveScopedValues(modelOnSuccessStep, index.info); err != nil {
+			return nil, err
+		}
+		modelOnSuccessStep = index.canonicalPut(modelOnSuccessStep)
+	}
+
+	onFailure := job.OnFailure
+	if index.onFailure != nil {
+		onFailure = appendHookStep(onFailure, index.onFailure)
 	}
 
 	var modelOnFailureStep model.IStep
-	if job.OnFailure != nil {
-		modelOnFailureStep, err = job.OnFailure.Model()
+	if onFailure != nil {
+		modelOnFailureStep, err = onFailure.Model()
 		if err != nil {
 			return nil, err
 		}
+		if err := resolveScopedValues(modelOnFailureStep, index.info); err != nil {
+			return nil, err
+		}
+		modelOnFailureStep = index.canonicalPut(modelOnFailureStep)
 	}
 
 	return &model.Job{
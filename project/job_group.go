@@ -1,9 +1,5 @@
 package project
 
-import (
-	"fmt"
-)
-
 type JobGroup struct {
 	Name   string
 	After  JobGroups
@@ -64,7 +60,9 @@ func sortJobGroupsByBefore(groups JobGroups) (JobGroups, error) {
 			break
 		}
 		if !move {
-			return nil, fmt.Errorf("There are groups in circular positionaning")
+			return nil, groupsCycle(blocked, "is before", func(group *JobGroup) JobGroups {
+				return group.Before
+			})
 		}
 	}
 
@@ -141,7 +139,9 @@ func SortJobGroups(groups JobGroups) (JobGroups, error) {
 			break
 		}
 		if !move {
-			return nil, fmt.Errorf("There are groups in circular positionaning")
+			return nil, groupsCycle(blocked, "is after", func(group *JobGroup) JobGroups {
+				return group.After
+			})
 		}
 	}
 
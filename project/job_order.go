@@ -0,0 +1,40 @@
+package project
+
+import "log"
+
+// The order of the jobs of one render: every job with the jobs it runs after and why.
+// The orders implied by the resources are kept here instead of in the jobs,
+// so jobs shared between pipelines are never changed while rendering.
+type jobOrder map[*Job]map[*Job]string
+
+// The order declared in the jobs with AddJobToRunAfter
+func explicitJobOrder(jobs Jobs) jobOrder {
+	order := make(jobOrder, len(jobs))
+	for _, job := range jobs {
+		order.addExplicit(job)
+	}
+	return order
+}
+
+func (o jobOrder) addExplicit(job *Job) {
+	if _, ok := o[job]; !ok {
+		o[job] = make(map[*Job]string, len(job.AfterJobs))
+	}
+
+	for afterJob := range job.AfterJobs {
+		if _, ok := o[job][afterJob]; !ok {
+			o[job][afterJob] = job.afterReasons[afterJob]
+		}
+	}
+}
+
+func (o jobOrder) add(job *Job, afterJob *Job, reason string) {
+	if _, ok := o[job]; !ok {
+		o[job] = make(map[*Job]string)
+	}
+
+	if _, ok := o[job][afterJob]; !ok {
+		log.Printf("Run job %s after %s: %s", job.Name, afterJob.Name, reason)
+		o[job][afterJob] = reason
+	}
+}
@@ -0,0 +1,89 @@
+package project
+
+import (
+	"sort"
+
+	"github.com/concourse-friends/concourse-builder/model"
+)
+
+// The minimal set of upstream jobs the resource flows through to the job.
+// Every branch of the after jobs graph is followed up to the first job that uses the resource,
+// so a job that joins several branches gets the same version as all of them.
+// Jobs that are upstream of another found job are dropped, passing the later one implies them.
+func flowJobs(job *Job, index *ResourceIndex, input *JobResource) Jobs {
+	found := make(JobsSet)
+	visited := make(JobsSet)
+
+	var walk func(job *Job)
+	walk = func(job *Job) {
+		for _, afterJob := range index.AfterJobs(job) {
+			if _, ok := visited[afterJob]; ok {
+				continue
+			}
+			visited[afterJob] = struct{}{}
+
+			if index.Uses(afterJob, input.Name) {
+				found[afterJob] = struct{}{}
+				continue
+			}
+
+			walk(afterJob)
+		}
+	}
+	walk(job)
+
+	var minimal Jobs
+	for candidate := range found {
+		implied := false
+		for other := range found {
+			if other == candidate {
+				continue
+			}
+			if _, ok := index.Upstream(other)[candidate]; ok {
+				implied = true
+				break
+			}
+		}
+		if !implied {
+			minimal = append(minimal, candidate)
+		}
+	}
+	sort.Sort(minimal)
+
+	return minimal
+}
+
+// The jobs the resource has to pass and why they were chosen
+func passedJobs(job *Job, index *ResourceIndex, input *JobResource) (model.JobNames, string) {
+	if input.NoPassed {
+		return nil, "declared without passed constraint"
+	}
+
+	if len(input.Passed) > 0 {
+		var passed model.JobNames
+		for _, job := range input.Passed {
+			passed = append(passed, model.JobName(job.Name))
+		}
+		sort.Slice(passed, func(i, j int) bool {
+			return passed[i] < passed[j]
+		})
+		return passed, "declared explicitly"
+	}
+
+	flow := flowJobs(job, index, input)
+	if len(flow) == 0 {
+		return nil, "no upstream job uses the resource"
+	}
+
+	var passed model.JobNames
+	for _, job := range flow {
+		passed = append(passed, model.JobName(job.Name))
+	}
+
+	reason := "the resource flows through the closest upstream jobs using it"
+	if len(flow) > 1 {
+		reason = "the resource flows through several upstream branches, all of them are passed"
+	}
+
+	return passed, reason
+}
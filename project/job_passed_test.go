@@ -0,0 +1,59 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassedJobsFanIn(t *testing.T) {
+	git := &JobResource{Name: "git"}
+	usesGit := func(name JobName) *Job {
+		return &Job{
+			Name:  name,
+			Steps: ISteps{&testStep{inputs: JobResources{git}}},
+		}
+	}
+
+	// a -> b -------> d
+	//   \-> c -> e -/
+	// c does not use git, so the git of d has to pass both b and e
+	a := usesGit("a")
+	b := usesGit("b")
+	c := &Job{Name: "c"}
+	e := usesGit("e")
+	d := usesGit("d")
+
+	b.AddJobToRunAfter(a)
+	c.AddJobToRunAfter(a)
+	e.AddJobToRunAfter(c)
+	d.AddJobToRunAfter(b, e)
+
+	index, err := NewResourceIndex(Jobs{a, b, c, d, e})
+	require.NoError(t, err)
+
+	passed, _ := passedJobs(d, index, git)
+	assert.Equal(t, model.JobNames{"b", "e"}, passed)
+
+	passed, _ = passedJobs(e, index, git)
+	assert.Equal(t, model.JobNames{"a"}, passed)
+
+	passed, reason := passedJobs(a, index, git)
+	assert.Nil(t, passed)
+	assert.Equal(t, "no upstream job uses the resource", reason)
+
+	// a is upstream of b, passing b implies it
+	d.AddJobToRunAfter(a)
+	index, err = NewResourceIndex(Jobs{a, b, c, d, e})
+	require.NoError(t, err)
+	passed, _ = passedJobs(d, index, git)
+	assert.Equal(t, model.JobNames{"b", "e"}, passed)
+
+	// jobs that are not rendered in the pipeline are not passed
+	index, err = NewResourceIndex(Jobs{a, b, c, d})
+	require.NoError(t, err)
+	passed, _ = passedJobs(d, index, git)
+	assert.Equal(t, model.JobNames{"b"}, passed)
+}
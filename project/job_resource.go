@@ -1,6 +1,7 @@
 package project
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 
@@ -18,11 +19,26 @@ type IJobResourceSource interface {
 	ModelSource(scope Scope, info *ScopeInfo) interface{}
 }
 
+// Optionally implemented by the sources that can be misconfigured, checked before the source is rendered
+type ISourceValidator interface {
+	Validate() error
+}
+
 type JobResource struct {
 	Name          ResourceName
 	PreferredPath string
 	Trigger       bool
 	GetParams     interface{}
+
+	// Jobs the resource has to pass before it gets to the job.
+	// When set it overrides the constraint computed from the job columns.
+	Passed Jobs
+
+	// Do not constrain the resource with passed jobs at all
+	NoPassed bool
+
+	// The version selection strategy of the get, e.g. "every" to run the job for each version
+	Version string
 }
 
 func (jr *JobResource) Path() string {
@@ -32,9 +48,15 @@ func (jr *JobResource) Path() string {
 	return string(jr.Name)
 }
 
-func (jr *JobResource) Model(info *ScopeInfo, registry *ResourceRegistry) *model.Resource {
+func (jr *JobResource) Model(info *ScopeInfo, registry *ResourceRegistry) (*model.Resource, error) {
 	res := registry.MustGetResource(jr.Name)
 
+	if validator, ok := res.Source.(ISourceValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, fmt.Errorf("Resource %s: %s", res.Name, err.Error())
+		}
+	}
+
 	modelResource := &model.Resource{
 		Name:       model.ResourceName(jr.Name),
 		Type:       model.ResourceTypeName(res.Type),
@@ -42,10 +64,10 @@ func (jr *JobResource) Model(info *ScopeInfo, registry *ResourceRegistry) *model
 	}
 
 	if res.Source != nil {
-		modelResource.Source = res.Source.ModelSource(res.Scope, info)
+		modelResource.Source = res.Source.ModelSource(res.Scope, info.WithNaming(res.Naming))
 	}
 
-	return modelResource
+	return modelResource, nil
 }
 
 type JobResources []*JobResource
@@ -62,33 +84,56 @@ func (jr JobResources) Less(i, j int) bool {
 	return jr[i].Name < jr[j].Name
 }
 
+// Merges the resources with the same name. The result is sorted by name.
+// Neither the slice nor the resources are changed, merged resources are copies,
+// so the resources of shared jobs can be deduplicated concurrently.
 func (jr JobResources) Deduplicate() JobResources {
 	if len(jr) == 0 {
 		return jr
 	}
 
-	sort.Sort(jr)
-
-	pos := 0
-	for i := range jr {
-		if pos == i {
+	sorted := make(JobResources, len(jr))
+	copy(sorted, jr)
+	sort.Stable(sorted)
+
+	result := make(JobResources, 0, len(sorted))
+	merged := false
+	for _, resource := range sorted {
+		last := len(result) - 1
+		if last < 0 || result[last].Name != resource.Name {
+			result = append(result, resource)
+			merged = false
 			continue
 		}
 
-		if jr[pos].Name != jr[i].Name {
-			pos++
-			jr[pos] = jr[i]
-			continue
+		if !merged {
+			copied := *result[last]
+			copied.Passed = append(Jobs(nil), copied.Passed...)
+			result[last] = &copied
+			merged = true
 		}
 
-		jr[pos].Trigger = jr[pos].Trigger || jr[i].Trigger
-		if jr[pos].PreferredPath == "" {
-			jr[pos].PreferredPath = jr[i].PreferredPath
-		}
-		if jr[pos].GetParams == nil {
-			jr[pos].GetParams = jr[i].GetParams
-		}
+		result[last].merge(resource)
 	}
 
-	return jr[:pos+1]
+	return result
+}
+
+func (jr *JobResource) merge(other *JobResource) {
+	jr.Trigger = jr.Trigger || other.Trigger
+	if jr.PreferredPath == "" {
+		jr.PreferredPath = other.PreferredPath
+	}
+	if jr.GetParams == nil {
+		jr.GetParams = other.GetParams
+	}
+	if jr.Version == "" {
+		jr.Version = other.Version
+	}
+	jr.NoPassed = jr.NoPassed || other.NoPassed
+	for _, job := range other.Passed {
+		if !jr.Passed.Contains(job) {
+			jr.Passed = append(jr.Passed, job)
+		}
+	}
 }
@@ -0,0 +1,7 @@
+package project
+
+// Makes the job cron-like, it is triggered by the time resource on its schedule.
+// The time resource is registered in the registry of the pipeline, so every pipeline checks its own schedule.
+func (job *Job) RunOnSchedule(resourceRegistry *ResourceRegistry, timeResource *Resource) {
+	job.ExtraResources = append(job.ExtraResources, resourceRegistry.JobResource(timeResource, true, nil))
+}
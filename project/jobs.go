@@ -1,7 +1,6 @@
 package project
 
 import (
-	"fmt"
 	"sort"
 
 	"github.com/concourse-friends/concourse-builder/model"
@@ -21,7 +20,12 @@ func (jobs Jobs) Less(i, j int) bool {
 	return jobs[i].Name < jobs[j].Name
 }
 
+// Sorts the jobs in columns, every job is in a column after the columns of the jobs it runs after
 func (jobs Jobs) SortByColumns() ([]Jobs, error) {
+	return jobs.sortByColumns(explicitJobOrder(jobs))
+}
+
+func (jobs Jobs) sortByColumns(order jobOrder) ([]Jobs, error) {
 	blocked := make(map[*Job]struct{})
 	mustHave := make(map[*Job]struct{})
 
@@ -29,15 +33,15 @@ func (jobs Jobs) SortByColumns() ([]Jobs, error) {
 	var column Jobs
 
 	for _, job := range jobs {
-		if len(job.AfterJobs) == 0 {
+		if len(order[job]) == 0 {
 			column = append(column, job)
 			continue
 		}
 
 		mustHave[job] = struct{}{}
 		blocked[job] = struct{}{}
-		for afterJob := range job.AfterJobs {
-			if len(afterJob.AfterJobs) > 0 {
+		for afterJob := range order[job] {
+			if len(order[afterJob]) > 0 {
 				blocked[afterJob] = struct{}{}
 			}
 		}
@@ -53,7 +57,7 @@ func (jobs Jobs) SortByColumns() ([]Jobs, error) {
 		unblocked := make(map[*Job]struct{})
 		for job := range blocked {
 			stillBlocked := false
-			for afterJob := range job.AfterJobs {
+			for afterJob := range order[job] {
 				if _, ok := blocked[afterJob]; ok {
 					stillBlocked = true
 					break
@@ -85,7 +89,7 @@ func (jobs Jobs) SortByColumns() ([]Jobs, error) {
 			break
 		}
 		if !move {
-			return nil, fmt.Errorf("There are jobs in circular positionaning")
+			return nil, jobsCycle(blocked, order)
 		}
 	}
 
@@ -114,8 +118,25 @@ func (jobs Jobs) NamesOfUsingResourceJobs(resource *JobResource) (model.JobNames
 	return jobNames, nil
 }
 
+func (jobs Jobs) Contains(job *Job) bool {
+	for _, j := range jobs {
+		if j == job {
+			return true
+		}
+	}
+	return false
+}
+
 type JobsSet map[*Job]struct{}
 
+func (jobs Jobs) Set() JobsSet {
+	set := make(JobsSet, len(jobs))
+	for _, job := range jobs {
+		set[job] = struct{}{}
+	}
+	return set
+}
+
 func (hs JobsSet) Pop() *Job {
 	for job := range hs {
 		delete(hs, job)
@@ -1,8 +1,10 @@
 package project
 
 import (
+	"fmt"
 	"io"
 	"log"
+	"regexp"
 	"sort"
 
 	"github.com/concourse-friends/concourse-builder/model"
@@ -27,6 +29,19 @@ type Pipeline struct {
 
 	// List of external registries that might provide some of the resources
 	ReuseFromPipeline Pipelines
+
+	// Resources consumed from jobs of other pipelines
+	Dependencies PipelineDependencies
+
+	// Optional owner marked in the pipeline
+	Owner *PipelineOwner
+
+	// Optional filter of the jobs to render. Only the matching jobs and the jobs they depend on are rendered.
+	JobFilter *regexp.Regexp
+
+	// Optional step run when any job of the pipeline fails, after the failure hook of the job.
+	// It is added when the pipeline is rendered, the jobs shared with other pipelines are not changed.
+	OnFailure IStep
 }
 
 type Pipelines []*Pipeline
@@ -37,7 +52,21 @@ func NewPipeline() *Pipeline {
 	}
 }
 
+// Adds a step to run when any job of the pipeline fails, after the steps added before
+func (p *Pipeline) AddOnFailure(step IStep) {
+	p.OnFailure = appendHookStep(p.OnFailure, step)
+}
+
+// The pipeline that provides the resource, nil if the resource belongs to this pipeline
 func (p *Pipeline) ReuseResourceFrom(resource *Resource) *Pipeline {
+	if dependency := p.DependencyFor(resource); dependency != nil {
+		return dependency.Pipeline
+	}
+
+	if len(p.ReuseFromPipeline) == 0 {
+		return nil
+	}
+
 	hash := resource.MustHash()
 	for _, pipeline := range p.ReuseFromPipeline {
 		reuseResource := pipeline.ResourceRegistry.GetResourceByHash(hash)
@@ -48,19 +77,42 @@ func (p *Pipeline) ReuseResourceFrom(resource *Resource) *Pipeline {
 	return nil
 }
 
+// The jobs together with all jobs that produce the resources they need
 func (p *Pipeline) JobsFor(checkJobs JobsSet) (Jobs, error) {
+	jobs, _, err := p.jobsFor(checkJobs)
+	return jobs, err
+}
+
+// The jobs needed for the checked jobs and the order between them.
+// The jobs are not changed, so pipelines sharing jobs can be rendered concurrently.
+func (p *Pipeline) jobsFor(checkJobs JobsSet) (Jobs, jobOrder, error) {
 	jobs := make(map[JobName]*Job)
+	order := make(jobOrder)
 	for job := checkJobs.Pop(); job != nil; job = checkJobs.Pop() {
 		log.Printf("Check job %s resources", job.Name)
 		jobs[job.Name] = job
+		order.addExplicit(job)
 
-		resources, err := job.Resources()
+		resources, err := job.withPipelineHook(p.OnFailure).Resources()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		for _, resource := range resources {
-			projectResource := p.ResourceRegistry.MustGetResource(resource.Name)
+			for _, passedJob := range resource.Passed {
+				order.add(job, passedJob, fmt.Sprintf("resource %s passes it", resource.Name))
+				if _, exists := jobs[passedJob.Name]; exists {
+					continue
+				}
+				checkJobs[passedJob] = struct{}{}
+				jobs[passedJob.Name] = passedJob
+			}
+
+			projectResource := p.ResourceRegistry.GetResource(resource.Name)
+			if projectResource == nil {
+				return nil, nil, fmt.Errorf("Job %s uses resource %s, which is not registered in pipeline %s",
+					job.Name, resource.Name, p.Name)
+			}
 
 			needs := projectResource.NeededJobs()
 
@@ -74,7 +126,7 @@ func (p *Pipeline) JobsFor(checkJobs JobsSet) (Jobs, error) {
 					continue
 				}
 
-				job.AddJobToRunAfter(resJob)
+				order.add(job, resJob, fmt.Sprintf("needs resource %s", resource.Name))
 				if _, exists := jobs[resJob.Name]; exists {
 					continue
 				}
@@ -89,17 +141,52 @@ func (p *Pipeline) JobsFor(checkJobs JobsSet) (Jobs, error) {
 		sliceJobs = append(sliceJobs, job)
 	}
 
-	return sliceJobs, nil
+	return sliceJobs, order, nil
 }
 
 func (p *Pipeline) AllJobs() (Jobs, error) {
-	checkJobs := make(JobsSet)
+	return p.JobsFor(p.Jobs.Set())
+}
+
+// The jobs the render starts from
+func (p *Pipeline) rootJobs() (JobsSet, error) {
+	if p.JobFilter == nil {
+		return p.Jobs.Set(), nil
+	}
+
+	allJobs, err := p.AllJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make(JobsSet)
+	for _, job := range allJobs {
+		if p.JobFilter.MatchString(string(job.Name)) {
+			roots[job] = struct{}{}
+		}
+	}
+	return roots, nil
+}
+
+// Indexes all jobs of the pipeline for a render
+func (p *Pipeline) resourceIndex() (*ResourceIndex, error) {
+	roots, err := p.rootJobs()
+	if err != nil {
+		return nil, err
+	}
 
-	for _, job := range p.Jobs {
-		checkJobs[job] = struct{}{}
+	allJobs, order, err := p.jobsFor(roots)
+	if err != nil {
+		return nil, err
 	}
 
-	return p.JobsFor(checkJobs)
+	index, err := newResourceIndex(allJobs, order, p.ResourceRegistry, p.OnFailure)
+	if err != nil {
+		return nil, err
+	}
+
+	p.applyDependencyTriggers(index)
+	return index, nil
 }
 
 func (p *Pipeline) ModelGroups(allJobs Jobs) (model.Groups, error) {
@@ -165,28 +252,10 @@ func (p *Pipeline) ModelGroups(allJobs Jobs) (model.Groups, error) {
 	return modelGroups, nil
 }
 
-func resources(allJobs Jobs) (JobResources, error) {
-	var jobResources JobResources
-	for _, job := range allJobs {
-		resources, err := job.Resources()
-		if err != nil {
-			return nil, err
-		}
-		jobResources = append(jobResources, resources...)
-	}
-
-	return jobResources.Deduplicate(), nil
-}
-
-func (p *Pipeline) ModelResourceTypes(info *ScopeInfo, allJobs Jobs) (model.ResourceTypes, error) {
-	jobResources, err := resources(allJobs)
-	if err != nil {
-		return nil, err
-	}
-
+func (p *Pipeline) ModelResourceTypes(info *ScopeInfo, index *ResourceIndex) (model.ResourceTypes, error) {
 	typesSet := make(map[ResourceTypeName]struct{})
 
-	for _, jobResource := range jobResources {
+	for _, jobResource := range index.Resources() {
 		res := p.ResourceRegistry.MustGetResource(jobResource.Name)
 		resourceType := GlobalTypeRegistry.RegisterType(res.Type)
 		if resourceType == nil {
@@ -225,21 +294,19 @@ func (p *Pipeline) ModelResourceTypes(info *ScopeInfo, allJobs Jobs) (model.Reso
 			}
 		}
 
-		modelResourceType := resourceType.Model(scope)
+		modelResourceType, err := resourceType.Model(scope)
+		if err != nil {
+			return nil, err
+		}
 		resourceTypes = append(resourceTypes, modelResourceType)
 	}
 
 	return resourceTypes, nil
 }
 
-func (p *Pipeline) ModelResources(info *ScopeInfo, allJobs Jobs) (model.Resources, error) {
-	jobResources, err := resources(allJobs)
-	if err != nil {
-		return nil, err
-	}
-
+func (p *Pipeline) ModelResources(info *ScopeInfo, index *ResourceIndex) (model.Resources, error) {
 	var resources model.Resources
-	for _, res := range jobResources {
+	for _, res := range index.Resources() {
 		projectResource := p.ResourceRegistry.MustGetResource(res.Name)
 
 		scope := info
@@ -251,24 +318,27 @@ func (p *Pipeline) ModelResources(info *ScopeInfo, allJobs Jobs) (model.Resource
 			}
 		}
 
-		modelResource := res.Model(scope, p.ResourceRegistry)
+		modelResource, err := res.Model(scope, p.ResourceRegistry)
+		if err != nil {
+			return nil, err
+		}
 		resources = append(resources, modelResource)
 	}
 
 	return resources, nil
 }
 
-func (p *Pipeline) ModelJobs(allJobs Jobs) (model.Jobs, error) {
-	columns, err := allJobs.SortByColumns()
+func (p *Pipeline) ModelJobs(index *ResourceIndex) (model.Jobs, error) {
+	columns, err := index.SortByColumns()
 	if err != nil {
 		return nil, err
 	}
 
-	modelJobs := make(model.Jobs, 0, len(allJobs))
+	modelJobs := make(model.Jobs, 0, len(index.Jobs()))
 
-	for i, column := range columns {
+	for _, column := range columns {
 		for _, job := range column {
-			modelJob, err := job.Model(columns[:i])
+			modelJob, err := job.Model(index)
 			if err != nil {
 				return nil, err
 			}
@@ -286,27 +356,37 @@ func (p *Pipeline) Save(team TeamName, installation InstallationName, writer io.
 		Installation: installation,
 	}
 
-	allJobs, err := p.AllJobs()
+	err := p.ValidateDependencies()
+	if err != nil {
+		return err
+	}
+
+	index, err := p.resourceIndex()
 	if err != nil {
 		return err
 	}
+	index.info = info
 
-	groups, err := p.ModelGroups(allJobs)
+	groups, err := p.ModelGroups(index.Jobs())
 	if err != nil {
 		return err
 	}
 
-	resourceTypes, err := p.ModelResourceTypes(info, allJobs)
+	resourceTypes, err := p.ModelResourceTypes(info, index)
 	if err != nil {
 		return err
 	}
 
-	resources, err := p.ModelResources(info, allJobs)
+	resources, err := p.ModelResources(info, index)
 	if err != nil {
 		return err
 	}
 
-	jobs, err := p.ModelJobs(allJobs)
+	if p.Owner != nil {
+		resources = append(resources, p.Owner.Model())
+	}
+
+	jobs, err := p.ModelJobs(index)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,125 @@
+package project
+
+import (
+	"fmt"
+)
+
+// How the consumed resource triggers the jobs of the consuming pipeline
+type DependencyTrigger int
+
+const (
+	// Every job triggers as its job resource declares
+	TriggerAsDeclared DependencyTrigger = iota
+
+	// New versions of the resource trigger every job that gets it
+	TriggerAlways
+
+	// New versions of the resource never trigger the jobs
+	TriggerNever
+)
+
+// A resource the pipeline consumes from a job of another pipeline, an S3 artifact,
+// an image or a git tag for example. The consuming pipeline renders the resource scoped
+// as in the producing pipeline and does not include the jobs that produce it.
+type PipelineDependency struct {
+	// The pipeline that produces the resource
+	Pipeline *Pipeline
+
+	// The job of the pipeline that produces the resource
+	Job *Job
+
+	// The produced resource
+	Resource *Resource
+
+	// How the resource triggers the consuming jobs
+	Trigger DependencyTrigger
+}
+
+type PipelineDependencies []*PipelineDependency
+
+// Declares that the pipeline consumes the resource the job of another pipeline produces
+func (p *Pipeline) ConsumeFrom(pipeline *Pipeline, job *Job, resource *Resource) *PipelineDependency {
+	dependency := &PipelineDependency{
+		Pipeline: pipeline,
+		Job:      job,
+		Resource: resource,
+	}
+	p.Dependencies = append(p.Dependencies, dependency)
+	return dependency
+}
+
+// The dependency that provides the resource, nil if the resource is not consumed from another pipeline
+func (p *Pipeline) DependencyFor(resource *Resource) *PipelineDependency {
+	if len(p.Dependencies) == 0 {
+		return nil
+	}
+
+	hash := resource.MustHash()
+	for _, dependency := range p.Dependencies {
+		if dependency.Resource.MustHash() == hash {
+			return dependency
+		}
+	}
+	return nil
+}
+
+// Checks that the producing pipelines really contain the producer jobs and that the jobs produce the resources
+func (p *Pipeline) ValidateDependencies() error {
+	for _, dependency := range p.Dependencies {
+		if dependency.Pipeline == nil || dependency.Job == nil || dependency.Resource == nil {
+			return fmt.Errorf("Pipeline %s has incomplete dependency", p.Name)
+		}
+
+		producerJobs, err := dependency.Pipeline.AllJobs()
+		if err != nil {
+			return err
+		}
+
+		if !producerJobs.Contains(dependency.Job) {
+			return fmt.Errorf("Pipeline %s consumes %s from job %s, but pipeline %s does not contain the job",
+				p.Name, dependency.Resource.Name, dependency.Job.Name, dependency.Pipeline.Name)
+		}
+
+		produced := dependency.Pipeline.ResourceRegistry.GetResourceByHash(dependency.Resource.MustHash())
+		if produced == nil {
+			return fmt.Errorf("Pipeline %s consumes %s from pipeline %s, but the pipeline does not have the resource",
+				p.Name, dependency.Resource.Name, dependency.Pipeline.Name)
+		}
+
+		outputs, err := dependency.Job.OutputResources()
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, output := range outputs {
+			if dependency.Pipeline.ResourceRegistry.CanonicalName(output.Name) == produced.Name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("Pipeline %s consumes %s from job %s of pipeline %s, but the job does not put it",
+				p.Name, dependency.Resource.Name, dependency.Job.Name, dependency.Pipeline.Name)
+		}
+	}
+
+	return nil
+}
+
+// Applies the trigger of the dependencies to the get steps of the consumed resources
+func (p *Pipeline) applyDependencyTriggers(index *ResourceIndex) {
+	for _, dependency := range p.Dependencies {
+		if dependency.Trigger == TriggerAsDeclared {
+			continue
+		}
+
+		resource := p.ResourceRegistry.GetResourceByHash(dependency.Resource.MustHash())
+		if resource == nil {
+			continue
+		}
+
+		index.setTrigger(resource.Name, dependency.Trigger == TriggerAlways)
+	}
+}
@@ -0,0 +1,100 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testScopedSource struct {
+	Bucket string
+}
+
+func (ts *testScopedSource) ModelSource(scope Scope, info *ScopeInfo) interface{} {
+	return map[string]string{
+		"bucket": ts.Bucket,
+		"key":    info.Scope(scope, "/") + "artifact.tgz",
+	}
+}
+
+func testDependencyPipelines() (*Pipeline, *Job, *Pipeline, *Resource) {
+	newArtifact := func() *Resource {
+		return &Resource{
+			Name:   "artifact",
+			Type:   "graph-test",
+			Source: &testScopedSource{Bucket: "artifacts"},
+			Scope:  TeamScope,
+		}
+	}
+
+	producer := NewPipeline()
+	producer.Name = "producer"
+	artifact := newArtifact()
+	producer.ResourceRegistry.MustRegister(artifact)
+
+	build := &Job{
+		Name:  "build",
+		Steps: ISteps{&testStep{output: artifact}},
+	}
+	artifact.NeedJobs(build)
+	producer.Jobs = Jobs{build}
+
+	consumer := NewPipeline()
+	consumer.Name = "consumer"
+	consumed := newArtifact()
+	consumed.NeedJobs(build)
+	consumer.Jobs = Jobs{
+		&Job{
+			Name: "deploy",
+			Steps: ISteps{
+				&testStep{inputs: JobResources{consumer.ResourceRegistry.JobResource(consumed, false, nil)}},
+			},
+		},
+	}
+
+	return producer, build, consumer, consumed
+}
+
+func TestPipelineDependency(t *testing.T) {
+	producer, build, consumer, consumed := testDependencyPipelines()
+	dependency := consumer.ConsumeFrom(producer, build, consumed)
+	dependency.Trigger = TriggerAlways
+
+	rendered := saveToString(t, consumer)
+	assert.NotContains(t, rendered, "name: build")
+	assert.Contains(t, rendered, "key: producer/artifact.tgz")
+	assert.Contains(t, rendered, "trigger: true")
+
+	graph, err := consumer.Graph()
+	require.NoError(t, err)
+	require.Len(t, graph.Edges, 1)
+	assert.Equal(t, "build", graph.Edges[0].From.Label)
+	assert.Equal(t, "pipeline producer", graph.Edges[0].From.Cluster)
+	assert.True(t, graph.Edges[0].CrossPipeline)
+}
+
+func TestPipelineDependencyWithoutProducerJob(t *testing.T) {
+	producer, build, consumer, consumed := testDependencyPipelines()
+	producer.Jobs = nil
+	consumer.ConsumeFrom(producer, build, consumed)
+
+	err := consumer.Save("team", "installation", &testDiscard{})
+	assert.EqualError(t, err, "Pipeline consumer consumes artifact from job build, "+
+		"but pipeline producer does not contain the job")
+
+	producer.Jobs = Jobs{build}
+	other := &Job{Name: "other"}
+	producer.Jobs = append(producer.Jobs, other)
+	consumer.Dependencies[0].Job = other
+
+	err = consumer.Save("team", "installation", &testDiscard{})
+	assert.EqualError(t, err, "Pipeline consumer consumes artifact from job other of pipeline producer, "+
+		"but the job does not put it")
+}
+
+type testDiscard struct{}
+
+func (td *testDiscard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,65 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/concourse-friends/concourse-builder/model"
+)
+
+// Why a get step of a job is constrained with its passed jobs
+type PassedExplanation struct {
+	Job      JobName
+	Resource ResourceName
+	Passed   model.JobNames
+	Reason   string
+}
+
+func (pe *PassedExplanation) String() string {
+	passed := make([]string, 0, len(pe.Passed))
+	for _, name := range pe.Passed {
+		passed = append(passed, string(name))
+	}
+	return fmt.Sprintf("%s: %s passed [%s]: %s", pe.Job, pe.Resource, strings.Join(passed, ", "), pe.Reason)
+}
+
+type PassedExplanations []*PassedExplanation
+
+func (pe PassedExplanations) String() string {
+	lines := make([]string, 0, len(pe))
+	for _, explanation := range pe {
+		lines = append(lines, explanation.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Explains the passed constraints of all get steps, in the order the jobs are rendered
+func (p *Pipeline) ExplainPassed() (PassedExplanations, error) {
+	index, err := p.resourceIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := index.SortByColumns()
+	if err != nil {
+		return nil, err
+	}
+
+	var explanations PassedExplanations
+	for _, column := range columns {
+		for _, job := range column {
+			for _, input := range index.Inputs(job) {
+				passed, reason := passedJobs(job, index, input)
+
+				explanations = append(explanations, &PassedExplanation{
+					Job:      job.Name,
+					Resource: input.Name,
+					Passed:   passed,
+					Reason:   reason,
+				})
+			}
+		}
+	}
+
+	return explanations, nil
+}
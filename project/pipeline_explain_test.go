@@ -0,0 +1,120 @@
+package project
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func testPassedPipeline(git func(pipeline *Pipeline, lint *Job) *JobResource) *Pipeline {
+	pipeline := NewPipeline()
+	pipeline.Name = "passed"
+
+	image := &Resource{Name: "image", Type: "graph-test", Source: &testSource{Id: "passed-image"}}
+
+	newGit := func() *JobResource {
+		return pipeline.ResourceRegistry.JobResource(
+			&Resource{Name: "git", Type: "graph-test", Source: &testSource{Id: "passed-git"}}, true, nil)
+	}
+
+	lint := &Job{
+		Name: "lint",
+		Steps: ISteps{
+			&testStep{inputs: JobResources{newGit()}},
+		},
+	}
+
+	build := &Job{
+		Name: "build",
+		Steps: ISteps{
+			&testStep{inputs: JobResources{newGit()}, output: image},
+		},
+	}
+	image.NeedJobs(build)
+
+	testGit := git(pipeline, lint)
+
+	test := &Job{
+		Name: "test",
+		Steps: ISteps{
+			&testStep{
+				inputs: JobResources{
+					pipeline.ResourceRegistry.JobResource(image, true, nil),
+					testGit,
+				},
+			},
+		},
+	}
+
+	pipeline.Jobs = append(pipeline.Jobs, test)
+	return pipeline
+}
+
+func TestExplainPassedComputed(t *testing.T) {
+	explanations, err := testPassedPipeline(func(pipeline *Pipeline, lint *Job) *JobResource {
+		pipeline.Jobs = append(pipeline.Jobs, lint)
+		return pipeline.ResourceRegistry.JobResource(
+			&Resource{Name: "git", Type: "graph-test", Source: &testSource{Id: "passed-git"}}, true, nil)
+	}).ExplainPassed()
+	require.NoError(t, err)
+
+	assert.Equal(t, "build: git passed []: no upstream job uses the resource\n"+
+		"lint: git passed []: no upstream job uses the resource\n"+
+		"test: git passed [build]: the resource flows through the closest upstream jobs using it\n"+
+		"test: image passed [build]: the resource flows through the closest upstream jobs using it",
+		explanations.String())
+}
+
+func TestExplainPassedDeclared(t *testing.T) {
+	pipeline := testPassedPipeline(func(pipeline *Pipeline, lint *Job) *JobResource {
+		git := pipeline.ResourceRegistry.JobResource(
+			&Resource{Name: "git", Type: "graph-test", Source: &testSource{Id: "passed-git"}}, true, nil)
+		git.Passed = Jobs{lint}
+		return git
+	})
+
+	explanations, err := pipeline.ExplainPassed()
+	require.NoError(t, err)
+
+	assert.Equal(t, "build: git passed []: no upstream job uses the resource\n"+
+		"lint: git passed []: no upstream job uses the resource\n"+
+		"test: git passed [lint]: declared explicitly\n"+
+		"test: image passed [build]: the resource flows through the closest upstream jobs using it",
+		explanations.String())
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+
+	rendered := struct {
+		Jobs []struct {
+			Name string
+			Plan []struct {
+				Aggregate []struct {
+					Get    string
+					Passed []string
+				}
+			}
+		}
+	}{}
+	require.NoError(t, yaml.Unmarshal(yml.Bytes(), &rendered))
+	require.Len(t, rendered.Jobs, 3)
+	assert.Equal(t, "test", rendered.Jobs[2].Name)
+	assert.Equal(t, []string{"lint"}, rendered.Jobs[2].Plan[0].Aggregate[0].Passed)
+	assert.Equal(t, []string{"build"}, rendered.Jobs[2].Plan[0].Aggregate[1].Passed)
+}
+
+func TestExplainPassedNoPassed(t *testing.T) {
+	explanations, err := testPassedPipeline(func(pipeline *Pipeline, lint *Job) *JobResource {
+		pipeline.Jobs = append(pipeline.Jobs, lint)
+		git := pipeline.ResourceRegistry.JobResource(
+			&Resource{Name: "git", Type: "graph-test", Source: &testSource{Id: "passed-git"}}, true, nil)
+		git.NoPassed = true
+		return git
+	}).ExplainPassed()
+	require.NoError(t, err)
+
+	assert.Equal(t, "test: git passed []: declared without passed constraint", explanations[2].String())
+}
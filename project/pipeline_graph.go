@@ -0,0 +1,367 @@
+package project
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type GraphNodeKind int
+
+const (
+	// A job of the pipeline
+	JobNode GraphNodeKind = iota
+
+	// A resource that is not produced by any job of the pipeline
+	ResourceNode
+
+	// A job of a pipeline the resources are reused from
+	ExternalJobNode
+)
+
+type GraphNode struct {
+	// Unique identifier of the node in the graph
+	ID string
+
+	// What the node is shown as
+	Label string
+
+	Kind GraphNodeKind
+
+	// The cluster the node is drawn in, empty if none
+	Cluster string
+}
+
+type GraphEdge struct {
+	From *GraphNode
+	To   *GraphNode
+
+	// The resource that flows along the edge
+	Resource ResourceName
+
+	// New versions of the resource trigger the job
+	Trigger bool
+
+	// The resource is constrained to pass the job the edge comes from
+	Passed bool
+
+	// The resource is reused from another pipeline
+	CrossPipeline bool
+}
+
+// The job dependency graph of a pipeline
+type PipelineGraph struct {
+	Name PipelineName
+
+	Nodes []*GraphNode
+	Edges []*GraphEdge
+
+	// Clusters in order of appearance
+	Clusters []string
+
+	nodes map[string]*GraphNode
+}
+
+func (g *PipelineGraph) node(key string, label string, kind GraphNodeKind, cluster string) *GraphNode {
+	if node, ok := g.nodes[key]; ok {
+		return node
+	}
+
+	if cluster != "" {
+		known := false
+		for _, c := range g.Clusters {
+			if c == cluster {
+				known = true
+				break
+			}
+		}
+		if !known {
+			g.Clusters = append(g.Clusters, cluster)
+		}
+	}
+
+	node := &GraphNode{
+		ID:      fmt.Sprintf("n%d", len(g.Nodes)),
+		Label:   label,
+		Kind:    kind,
+		Cluster: cluster,
+	}
+	g.nodes[key] = node
+	g.Nodes = append(g.Nodes, node)
+	return node
+}
+
+func (g *PipelineGraph) jobNode(job *Job) *GraphNode {
+	// A node can be drawn only in one cluster, so the jobs are drawn in their first group
+	cluster := ""
+	if len(job.Groups) > 0 {
+		cluster = job.Groups[0].Name
+	}
+	return g.node("job:"+string(job.Name), string(job.Name), JobNode, cluster)
+}
+
+func (g *PipelineGraph) externalJobNode(pipeline *Pipeline, job *Job) *GraphNode {
+	return g.node(
+		"external:"+string(pipeline.Name)+":"+string(job.Name),
+		string(job.Name),
+		ExternalJobNode,
+		"pipeline "+string(pipeline.Name))
+}
+
+func (g *PipelineGraph) resourceNode(pipeline *Pipeline, name ResourceName) *GraphNode {
+	if pipeline == nil {
+		return g.node("resource:"+string(name), string(name), ResourceNode, "")
+	}
+	return g.node(
+		"external-resource:"+string(pipeline.Name)+":"+string(name),
+		string(name),
+		ResourceNode,
+		"pipeline "+string(pipeline.Name))
+}
+
+func (g *PipelineGraph) nodesInCluster(cluster string) []*GraphNode {
+	var nodes []*GraphNode
+	for _, node := range g.Nodes {
+		if node.Cluster == cluster {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Builds the dependency graph of all jobs of the pipeline, the same way they are rendered
+func (p *Pipeline) Graph() (*PipelineGraph, error) {
+	index, err := p.resourceIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := index.SortByColumns()
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &PipelineGraph{
+		Name:  p.Name,
+		nodes: make(map[string]*GraphNode),
+	}
+
+	jobsByName := make(map[JobName]*Job, len(index.Jobs()))
+	for _, job := range index.Jobs() {
+		jobsByName[job.Name] = job
+	}
+
+	for _, column := range columns {
+		for _, job := range column {
+			graph.jobNode(job)
+		}
+	}
+
+	for _, column := range columns {
+		for _, job := range column {
+			to := graph.jobNode(job)
+
+			for _, input := range index.Inputs(job) {
+				passed, _ := passedJobs(job, index, input)
+
+				for _, name := range passed {
+					graph.Edges = append(graph.Edges, &GraphEdge{
+						From:     graph.jobNode(jobsByName[JobName(name)]),
+						To:       to,
+						Resource: input.Name,
+						Trigger:  input.Trigger,
+						Passed:   true,
+					})
+				}
+
+				if len(passed) > 0 {
+					continue
+				}
+
+				graph.Edges = append(graph.Edges, p.sourceEdges(graph, input, to)...)
+			}
+		}
+	}
+
+	return graph, nil
+}
+
+// The edges from where a resource without passed constraint comes from
+func (p *Pipeline) sourceEdges(graph *PipelineGraph, input *JobResource, to *GraphNode) []*GraphEdge {
+	resource := p.ResourceRegistry.MustGetResource(input.Name)
+
+	if dependency := p.DependencyFor(resource); dependency != nil {
+		return []*GraphEdge{
+			{
+				From:          graph.externalJobNode(dependency.Pipeline, dependency.Job),
+				To:            to,
+				Resource:      input.Name,
+				Trigger:       input.Trigger,
+				CrossPipeline: true,
+			},
+		}
+	}
+
+	reusePipeline := p.ReuseResourceFrom(resource)
+	if reusePipeline == nil {
+		return []*GraphEdge{
+			{
+				From:     graph.resourceNode(nil, input.Name),
+				To:       to,
+				Resource: input.Name,
+				Trigger:  input.Trigger,
+			},
+		}
+	}
+
+	var edges []*GraphEdge
+
+	reuseResource := reusePipeline.ResourceRegistry.GetResourceByHash(resource.MustHash())
+	for _, job := range reuseResource.NeededJobs() {
+		edges = append(edges, &GraphEdge{
+			From:          graph.externalJobNode(reusePipeline, job),
+			To:            to,
+			Resource:      input.Name,
+			Trigger:       input.Trigger,
+			CrossPipeline: true,
+		})
+	}
+
+	if len(edges) == 0 {
+		edges = append(edges, &GraphEdge{
+			From:          graph.resourceNode(reusePipeline, reuseResource.Name),
+			To:            to,
+			Resource:      input.Name,
+			Trigger:       input.Trigger,
+			CrossPipeline: true,
+		})
+	}
+
+	return edges
+}
+
+func (e *GraphEdge) label() string {
+	var flags []string
+	if e.Passed {
+		flags = append(flags, "passed")
+	}
+	if e.Trigger {
+		flags = append(flags, "trigger")
+	}
+
+	if len(flags) == 0 {
+		return string(e.Resource)
+	}
+	return fmt.Sprintf("%s (%s)", e.Resource, strings.Join(flags, ", "))
+}
+
+func dotQuote(str string) string {
+	return `"` + strings.Replace(strings.Replace(str, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+}
+
+func (n *GraphNode) dot() string {
+	switch n.Kind {
+	case ResourceNode:
+		return fmt.Sprintf("%s [label=%s, shape=ellipse, style=dashed];", n.ID, dotQuote(n.Label))
+	case ExternalJobNode:
+		return fmt.Sprintf("%s [label=%s, shape=box, style=dashed];", n.ID, dotQuote(n.Label))
+	default:
+		return fmt.Sprintf("%s [label=%s, shape=box];", n.ID, dotQuote(n.Label))
+	}
+}
+
+func (e *GraphEdge) dot() string {
+	var styles []string
+	if e.Trigger {
+		styles = append(styles, "bold")
+	}
+	if e.CrossPipeline || (!e.Passed && e.From.Kind == ResourceNode) {
+		styles = append(styles, "dashed")
+	}
+
+	attributes := "label=" + dotQuote(e.label())
+	if len(styles) > 0 {
+		attributes += ", style=" + dotQuote(strings.Join(styles, ","))
+	}
+
+	return fmt.Sprintf("%s -> %s [%s];", e.From.ID, e.To.ID, attributes)
+}
+
+// Writes the graph in graphviz DOT format. The clusters are the job groups and the reused pipelines.
+func (g *PipelineGraph) Dot(writer io.Writer) error {
+	lines := []string{
+		fmt.Sprintf("digraph %s {", dotQuote(string(g.Name))),
+		"  rankdir=LR;",
+	}
+
+	for i, cluster := range g.Clusters {
+		lines = append(lines,
+			fmt.Sprintf("  subgraph cluster_%d {", i),
+			fmt.Sprintf("    label=%s;", dotQuote(cluster)))
+		for _, node := range g.nodesInCluster(cluster) {
+			lines = append(lines, "    "+node.dot())
+		}
+		lines = append(lines, "  }")
+	}
+
+	for _, node := range g.nodesInCluster("") {
+		lines = append(lines, "  "+node.dot())
+	}
+
+	for _, edge := range g.Edges {
+		lines = append(lines, "  "+edge.dot())
+	}
+
+	lines = append(lines, "}")
+
+	_, err := io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func mermaidQuote(str string) string {
+	return `"` + strings.Replace(str, `"`, "#quot;", -1) + `"`
+}
+
+func (n *GraphNode) mermaid() string {
+	if n.Kind == ResourceNode {
+		return fmt.Sprintf("%s([%s])", n.ID, mermaidQuote(n.Label))
+	}
+	return fmt.Sprintf("%s[%s]", n.ID, mermaidQuote(n.Label))
+}
+
+func (e *GraphEdge) mermaid() string {
+	arrow := "-->"
+	if e.CrossPipeline {
+		arrow = "-.->"
+	} else if e.Trigger {
+		arrow = "==>"
+	}
+
+	return fmt.Sprintf("%s %s|%s| %s", e.From.ID, arrow, mermaidQuote(e.label()), e.To.ID)
+}
+
+// Writes the graph as a Mermaid flowchart. The clusters are drawn as subgraphs.
+func (g *PipelineGraph) Mermaid(writer io.Writer) error {
+	lines := []string{
+		"graph LR",
+	}
+
+	for i, cluster := range g.Clusters {
+		lines = append(lines, fmt.Sprintf("  subgraph c%d [%s]", i, mermaidQuote(cluster)))
+		for _, node := range g.nodesInCluster(cluster) {
+			lines = append(lines, "    "+node.mermaid())
+		}
+		lines = append(lines, "  end")
+	}
+
+	for _, node := range g.nodesInCluster("") {
+		lines = append(lines, "  "+node.mermaid())
+	}
+
+	for _, edge := range g.Edges {
+		lines = append(lines, "  "+edge.mermaid())
+	}
+
+	_, err := io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
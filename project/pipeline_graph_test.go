@@ -0,0 +1,137 @@
+package project
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSource struct {
+	Id string
+}
+
+func (ts *testSource) ModelSource(scope Scope, info *ScopeInfo) interface{} {
+	return ts
+}
+
+type testStep struct {
+	inputs JobResources
+	output *Resource
+}
+
+func (ts *testStep) Model() (model.IStep, error) {
+	return &model.Task{}, nil
+}
+
+func (ts *testStep) InputResources() (JobResources, error) {
+	return ts.inputs, nil
+}
+
+func (ts *testStep) OutputResource() (*Resource, error) {
+	return ts.output, nil
+}
+
+func init() {
+	GlobalTypeRegistry.MustRegisterType(&ResourceType{
+		Name: "graph-test",
+		Type: model.ResourceTypeTypeName(model.SystemResourceTypeName),
+	})
+}
+
+func testGraphPipeline() *Pipeline {
+	group := &JobGroup{Name: "images"}
+
+	reused := NewPipeline()
+	reused.Name = "builder"
+	reusedImage := &Resource{Name: "tools", Type: "graph-test", Source: &testSource{Id: "tools"}}
+	reused.ResourceRegistry.MustRegister(reusedImage)
+	reusedImage.NeedJobs(&Job{Name: "tools-image"})
+
+	pipeline := NewPipeline()
+	pipeline.Name = "main"
+	pipeline.ReuseFromPipeline = Pipelines{reused}
+
+	git := &Resource{Name: "git", Type: "graph-test", Source: &testSource{Id: "git"}}
+	image := &Resource{Name: "image", Type: "graph-test", Source: &testSource{Id: "image"}}
+	tools := &Resource{Name: "tools", Type: "graph-test", Source: &testSource{Id: "tools"}}
+
+	build := &Job{
+		Name:   "build",
+		Groups: JobGroups{group},
+		Steps: ISteps{
+			&testStep{
+				inputs: JobResources{
+					pipeline.ResourceRegistry.JobResource(git, true, nil),
+					pipeline.ResourceRegistry.JobResource(tools, false, nil),
+				},
+				output: image,
+			},
+		},
+	}
+	image.NeedJobs(build)
+
+	test := &Job{
+		Name: "test",
+		Steps: ISteps{
+			&testStep{
+				inputs: JobResources{
+					pipeline.ResourceRegistry.JobResource(image, true, nil),
+				},
+			},
+		},
+	}
+
+	pipeline.Jobs = Jobs{test}
+	return pipeline
+}
+
+func TestPipelineGraphDot(t *testing.T) {
+	graph, err := testGraphPipeline().Graph()
+	require.NoError(t, err)
+
+	dot := &bytes.Buffer{}
+	require.NoError(t, graph.Dot(dot))
+
+	assert.Equal(t, `digraph "main" {
+  rankdir=LR;
+  subgraph cluster_0 {
+    label="images";
+    n0 [label="build", shape=box];
+  }
+  subgraph cluster_1 {
+    label="pipeline builder";
+    n3 [label="tools-image", shape=box, style=dashed];
+  }
+  n1 [label="test", shape=box];
+  n2 [label="git", shape=ellipse, style=dashed];
+  n2 -> n0 [label="git (trigger)", style="bold,dashed"];
+  n3 -> n0 [label="tools", style="dashed"];
+  n0 -> n1 [label="image (passed, trigger)", style="bold"];
+}
+`, dot.String())
+}
+
+func TestPipelineGraphMermaid(t *testing.T) {
+	graph, err := testGraphPipeline().Graph()
+	require.NoError(t, err)
+
+	mermaid := &bytes.Buffer{}
+	require.NoError(t, graph.Mermaid(mermaid))
+
+	assert.Equal(t, `graph LR
+  subgraph c0 ["images"]
+    n0["build"]
+  end
+  subgraph c1 ["pipeline builder"]
+    n3["tools-image"]
+  end
+  n1["test"]
+  n2(["git"])
+  n2 ==>|"git (trigger)"| n0
+  n3 -.->|"tools"| n0
+  n0 ==>|"image (passed, trigger)"| n1
+`, mermaid.String())
+}
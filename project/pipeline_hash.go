@@ -0,0 +1,99 @@
+package project
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Hash of a pipeline configuration
+type PipelineHash string
+
+// The extension of the file, next to the rendered pipeline, that keeps the pipeline hash
+const PipelineHashExtension = ".sha256"
+
+// Concourse omits the empty fields of the configurations it returns
+func emptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// Brings a configuration to the form of the configurations concourse returns. The keys are strings,
+// the empty fields are omitted, the aggregate steps are parallel steps and the scalars are strings,
+// concourse keeps the task params as strings.
+func canonicalValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = item
+		}
+		return canonicalValue(result)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			item = canonicalValue(item)
+			if !emptyValue(item) {
+				result[key] = item
+			}
+		}
+
+		if steps, ok := result["aggregate"]; ok {
+			delete(result, "aggregate")
+			result["in_parallel"] = steps
+		}
+		if steps, ok := result["in_parallel"].([]interface{}); ok {
+			result["in_parallel"] = map[string]interface{}{"steps": steps}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = canonicalValue(item)
+		}
+		return result
+	case nil, bool, int, float64:
+		if emptyValue(v) {
+			return nil
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+func HashPipelineConfig(config []byte) (PipelineHash, error) {
+	var value interface{}
+	err := yaml.Unmarshal(config, &value)
+	if err != nil {
+		return "", err
+	}
+
+	canonical := &bytes.Buffer{}
+	encoder := json.NewEncoder(canonical)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(canonicalValue(value))
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(canonical.Bytes())
+	return PipelineHash(hex.EncodeToString(hash[:])), nil
+}
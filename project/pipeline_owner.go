@@ -0,0 +1,76 @@
+package project
+
+import (
+	"github.com/concourse-friends/concourse-builder/model"
+	"gopkg.in/yaml.v2"
+)
+
+// The name of the resource that marks the owner of a pipeline. The resource is never used nor checked,
+// it only carries the owner in its source, so the owner can be read back from the configuration of
+// the deployed pipeline. It is a time resource, the type every worker has.
+const OwnerResourceName = model.ResourceName("concourse-builder-owner")
+
+// The owner marker of the pipelines deployed before the marker became a resource
+const OwnerResourceTypeName = model.ResourceTypeName("concourse-builder-owner")
+
+const ownerResourceType = model.ResourceTypeName("time")
+
+// Who generated a pipeline. Cleanups touch only the pipelines they own.
+type PipelineOwner struct {
+	// Identifies the generator, all pipelines of a generator are managed together
+	Generator string `yaml:"generator" json:"generator"`
+
+	// The repo of the specification the pipeline is generated from
+	Repo string `yaml:"repo,omitempty" json:"repo,omitempty"`
+
+	// The branch of the specification the pipeline is generated from
+	Branch string `yaml:"branch,omitempty" json:"branch,omitempty"`
+}
+
+// The same generator owns the pipelines of both owners
+func (po *PipelineOwner) Owns(other *PipelineOwner) bool {
+	return po != nil && other != nil && po.Generator == other.Generator
+}
+
+func (po *PipelineOwner) Model() *model.Resource {
+	return &model.Resource{
+		Name:       OwnerResourceName,
+		Type:       ownerResourceType,
+		Source:     po,
+		CheckEvery: model.NeverDuration,
+	}
+}
+
+// Reads the owner marker from a pipeline configuration (yaml or json).
+// Returns nil if the pipeline has no owner.
+func PipelineOwnerFrom(config []byte) (*PipelineOwner, error) {
+	pipeline := &struct {
+		Resources []struct {
+			Name   model.ResourceName
+			Source *PipelineOwner
+		}
+		ResourceTypes []struct {
+			Name   model.ResourceTypeName
+			Source *PipelineOwner
+		} `yaml:"resource_types"`
+	}{}
+
+	err := yaml.Unmarshal(config, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range pipeline.Resources {
+		if resource.Name == OwnerResourceName && resource.Source != nil {
+			return resource.Source, nil
+		}
+	}
+
+	for _, resourceType := range pipeline.ResourceTypes {
+		if resourceType.Name == OwnerResourceTypeName && resourceType.Source != nil {
+			return resourceType.Source, nil
+		}
+	}
+
+	return nil, nil
+}
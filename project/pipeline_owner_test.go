@@ -0,0 +1,65 @@
+package project
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineOwnerMarker(t *testing.T) {
+	owner := &PipelineOwner{
+		Generator: "target-sdp",
+		Repo:      "git@github.com:target.git",
+		Branch:    "feature/foo",
+	}
+
+	pipeline := NewPipeline()
+	pipeline.Name = "foo-sdpb"
+	pipeline.Owner = owner
+	pipeline.Jobs = Jobs{
+		&Job{Name: "a"},
+	}
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+
+	// The marker is a resource concourse never checks, not a resource type
+	assert.Contains(t, yml.String(), "resources:\n- name: concourse-builder-owner\n  type: time\n")
+	assert.Contains(t, yml.String(), "  check_every: never\n")
+	assert.NotContains(t, yml.String(), "resource_types")
+
+	read, err := PipelineOwnerFrom(yml.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, owner, read)
+	assert.True(t, owner.Owns(read))
+
+	read, err = PipelineOwnerFrom([]byte(`{"resources":[{"name":"concourse-builder-owner",` +
+		`"type":"time","source":{"generator":"target-sdp"},"check_every":"never"}]}`))
+	require.NoError(t, err)
+	assert.True(t, owner.Owns(read))
+
+	// The marker of the pipelines marked before the marker became a resource
+	read, err = PipelineOwnerFrom([]byte(`{"resource_types":[{"name":"concourse-builder-owner",` +
+		`"type":"docker-image","source":{"generator":"target-sdp"}}]}`))
+	require.NoError(t, err)
+	assert.True(t, owner.Owns(read))
+	assert.False(t, owner.Owns(&PipelineOwner{Generator: "other-sdp"}))
+}
+
+func TestPipelineWithoutOwner(t *testing.T) {
+	pipeline := NewPipeline()
+	pipeline.Name = "foo"
+	pipeline.Jobs = Jobs{
+		&Job{Name: "a"},
+	}
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+
+	read, err := PipelineOwnerFrom(yml.Bytes())
+	require.NoError(t, err)
+	assert.Nil(t, read)
+	assert.False(t, (&PipelineOwner{Generator: "a"}).Owns(read))
+}
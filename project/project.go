@@ -1,38 +1,143 @@
 package project
 
 import (
+	"bytes"
 	"io/ioutil"
 	"log"
 	"os"
+	"path"
 	"regexp"
+	"runtime"
+	"sync"
 )
 
 var logger = log.New(os.Stdout, "", log.LstdFlags)
 
 type Project struct {
 	Pipelines Pipelines
+
+	// How many pipelines are rendered concurrently. By default as many as the CPUs.
+	RenderWorkers int
 }
 
-func (p *Project) Deploy(team TeamName, installation InstallationName) error {
-	for _, pipeline := range p.Pipelines {
-		tmpfile, err := ioutil.TempFile("", "pipeline")
+// Access to the pipelines deployed in concourse
+type IPipelineTarget interface {
+	// Returns the configuration of the deployed pipeline, nil if the pipeline does not exist
+	PipelineConfig(name PipelineName) ([]byte, error)
+
+	// Sets the configuration of the pipeline
+	SetPipelineConfig(name PipelineName, config []byte) error
+}
+
+// A pipeline rendered to its configuration
+type RenderedPipeline struct {
+	// The name of the pipeline
+	Name PipelineName
+
+	// The configuration of the pipeline
+	Config []byte
+
+	// The hash of the configuration
+	Hash PipelineHash
+}
+
+func renderPipeline(pipeline *Pipeline, team TeamName, installation InstallationName) (*RenderedPipeline, error) {
+	config := &bytes.Buffer{}
+	err := pipeline.Save(team, installation, config)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := HashPipelineConfig(config.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return &RenderedPipeline{
+		Name:   pipeline.Name,
+		Config: config.Bytes(),
+		Hash:   hash,
+	}, nil
+}
+
+func (p *Project) renderWorkers() int {
+	workers := p.RenderWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(p.Pipelines) {
+		workers = len(p.Pipelines)
+	}
+	return workers
+}
+
+// Renders every pipeline of the project concurrently. The pipelines are returned in the
+// order of the project. If rendering fails the error of the first failed pipeline is returned.
+func (p *Project) Render(team TeamName, installation InstallationName) ([]*RenderedPipeline, error) {
+	rendered := make([]*RenderedPipeline, len(p.Pipelines))
+	errs := make([]error, len(p.Pipelines))
+
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < p.renderWorkers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				rendered[i], errs[i] = renderPipeline(p.Pipelines[i], team, installation)
+			}
+		}()
+	}
+
+	for i := range p.Pipelines {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			logger.Printf("Pipeline %s can not be rendered", p.Pipelines[i].Name)
+			return nil, err
+		}
+	}
+
+	return rendered, nil
+}
+
+// Renders every pipeline and sets it in the target. Pipelines which configuration did not change
+// are skipped. If there is no target the pipelines are only rendered.
+func (p *Project) Deploy(team TeamName, installation InstallationName, target IPipelineTarget) error {
+	rendered, err := p.Render(team, installation)
+	if err != nil {
+		return err
+	}
+
+	for _, pipeline := range rendered {
+		if target == nil {
+			logger.Printf("Rendered pipeline %s with hash %s", pipeline.Name, pipeline.Hash)
+			continue
+		}
+
+		current, err := target.PipelineConfig(pipeline.Name)
 		if err != nil {
-			log.Panic(err)
+			return err
 		}
 
-		logger.Printf("Saving pipeline %s in %s", pipeline.Name, tmpfile.Name())
+		if current != nil {
+			currentHash, err := HashPipelineConfig(current)
+			if err != nil {
+				return err
+			}
 
-		defer func() {
-			if tmpfile != nil {
-				err = tmpfile.Close()
-				if err != nil {
-					logger.Printf("Warining: %s", err.Error())
-				}
+			if currentHash == pipeline.Hash {
+				logger.Printf("Pipeline %s is not changed, skipping", pipeline.Name)
+				continue
 			}
-		}()
-		defer os.Remove(tmpfile.Name())
+		}
 
-		err = pipeline.Save(team, installation, tmpfile)
+		logger.Printf("Setting pipeline %s", pipeline.Name)
+		err = target.SetPipelineConfig(pipeline.Name, pipeline.Config)
 		if err != nil {
 			return err
 		}
@@ -41,26 +146,40 @@ func (p *Project) Deploy(team TeamName, installation InstallationName) error {
 	return nil
 }
 
-// Removes every job from the project that does not match the pattern.
-// This allows for the creating simpler pipelines that get to the jobs of interest faster.
-// Purfect for debugging. Note that on save the piplines will self expand with all of the
-// dependencies needed for the jobs to work, witch will make them valid and operational.
-func (p *Project) Filter(jobRegex *regexp.Regexp) error {
-	for _, pipeline := range p.Pipelines {
-		jobs, err := pipeline.AllJobs()
+// Saves every pipeline in the directory as <pipeline>.yml with its hash next to it
+// in <pipeline>.yml.sha256
+func (p *Project) SaveTo(team TeamName, installation InstallationName, directory string) error {
+	rendered, err := p.Render(team, installation)
+	if err != nil {
+		return err
+	}
+
+	for _, pipeline := range rendered {
+		file := path.Join(directory, string(pipeline.Name)+".yml")
+		logger.Printf("Saving pipeline %s in %s", pipeline.Name, file)
+
+		err = ioutil.WriteFile(file, pipeline.Config, 0644)
 		if err != nil {
 			return err
 		}
 
-		for i := 0; i < len(jobs); {
-			if jobRegex.Match([]byte(jobs[i].Name)) {
-				i++
-			} else {
-				jobs = append(jobs[:i], jobs[i+1:]...)
-			}
+		err = ioutil.WriteFile(file+PipelineHashExtension, []byte(pipeline.Hash), 0644)
+		if err != nil {
+			return err
 		}
+	}
 
-		pipeline.Jobs = jobs
+	return nil
+}
+
+// Removes every job from the project that does not match the pattern.
+// This allows for the creating simpler pipelines that get to the jobs of interest faster.
+// Purfect for debugging. Note that on save the piplines will self expand with all of the
+// dependencies needed for the jobs to work, witch will make them valid and operational.
+// The filter is applied when the pipelines are rendered, the jobs of the pipelines stay as they are.
+func (p *Project) Filter(jobRegex *regexp.Regexp) error {
+	for _, pipeline := range p.Pipelines {
+		pipeline.JobFilter = jobRegex
 	}
 
 	return nil
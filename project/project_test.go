@@ -0,0 +1,221 @@
+package project
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPipelineTarget struct {
+	configs map[PipelineName][]byte
+	sets    int
+}
+
+func (t *testPipelineTarget) PipelineConfig(name PipelineName) ([]byte, error) {
+	return t.configs[name], nil
+}
+
+func (t *testPipelineTarget) SetPipelineConfig(name PipelineName, config []byte) error {
+	t.configs[name] = config
+	t.sets++
+	return nil
+}
+
+func TestHashPipelineConfig(t *testing.T) {
+	yml, err := HashPipelineConfig([]byte("jobs:\n- name: a\n  serial: true\ngroups: []\n"))
+	require.NoError(t, err)
+
+	json, err := HashPipelineConfig([]byte(`{"groups":[],"jobs":[{"serial":true,"name":"a"}]}`))
+	require.NoError(t, err)
+
+	other, err := HashPipelineConfig([]byte("jobs:\n- name: b\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, yml, json)
+	assert.NotEqual(t, yml, other)
+}
+
+// A rendered pipeline and the configuration concourse returns for it, the keys are reordered, the aggregate
+// step is a parallel step, the task params are strings and the defaults are filled in
+const (
+	testRenderedConfig = `groups:
+- name: all
+  jobs:
+  - build
+resources:
+- name: git
+  type: git
+  source:
+    uri: git@github.com:org/repo.git
+    branch: master
+  check_every: 24h
+jobs:
+- name: build
+  plan:
+  - aggregate:
+    - get: git
+      trigger: true
+  - task: test
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: ubuntu
+      params:
+        RETRIES: 3
+        VERBOSE: true
+      run:
+        path: /bin/bash
+        args:
+        - -c
+        - make
+  - put: git
+    params:
+      repository: git
+`
+
+	testDeployedConfig = `{"groups":[{"name":"all","jobs":["build"]}],` +
+		`"resources":[{"name":"git","type":"git","source":{"branch":"master","uri":"git@github.com:org/repo.git"},` +
+		`"check_every":"24h"}],"resource_types":[],` +
+		`"jobs":[{"name":"build","public":false,"serial":false,"max_in_flight":0,"plan":[` +
+		`{"in_parallel":{"steps":[{"get":"git","trigger":true}],"limit":0,"fail_fast":false}},` +
+		`{"task":"test","privileged":false,"config":{"platform":"linux",` +
+		`"image_resource":{"type":"docker-image","source":{"repository":"ubuntu"}},` +
+		`"params":{"RETRIES":"3","VERBOSE":"true"},"run":{"path":"/bin/bash","args":["-c","make"]}}},` +
+		`{"put":"git","params":{"repository":"git"}}]}]}`
+)
+
+func TestHashDeployedPipelineConfig(t *testing.T) {
+	rendered, err := HashPipelineConfig([]byte(testRenderedConfig))
+	require.NoError(t, err)
+
+	deployed, err := HashPipelineConfig([]byte(testDeployedConfig))
+	require.NoError(t, err)
+
+	changed, err := HashPipelineConfig([]byte(strings.Replace(testDeployedConfig, `"RETRIES":"3"`, `"RETRIES":"4"`, 1)))
+	require.NoError(t, err)
+
+	assert.Equal(t, rendered, deployed)
+	assert.NotEqual(t, rendered, changed)
+}
+
+// The fly image hashes the deployed pipelines with the same normalization
+func TestHashPipelineConfigMatchesFlyImage(t *testing.T) {
+	if _, err := exec.LookPath("jq"); err != nil {
+		t.Skip("jq is not installed")
+	}
+
+	cmd := exec.Command("bash", "-c", ". ../docker/fly/pipeline_hash.sh && pipeline_hash")
+	cmd.Stdin = strings.NewReader(testDeployedConfig)
+	output, err := cmd.Output()
+	require.NoError(t, err)
+
+	rendered, err := HashPipelineConfig([]byte(testRenderedConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(rendered), strings.TrimSpace(string(output)))
+}
+
+func TestProjectDeploySkipsPipelinesNormalizedByConcourse(t *testing.T) {
+	pipeline := NewPipeline()
+	pipeline.Name = "foo"
+	pipeline.Jobs = Jobs{
+		&Job{Name: "a"},
+	}
+
+	prj := &Project{
+		Pipelines: Pipelines{pipeline},
+	}
+
+	target := &testPipelineTarget{
+		configs: map[PipelineName][]byte{
+			"foo": []byte(`{"resources":[],"jobs":[{"public":false,"serial":false,"name":"a","plan":null}],"groups":null}`),
+		},
+	}
+
+	require.NoError(t, prj.Deploy("team", "installation", target))
+	assert.Equal(t, 0, target.sets)
+}
+
+func TestProjectDeploySkipsUnchangedPipelines(t *testing.T) {
+	pipeline := NewPipeline()
+	pipeline.Name = "foo"
+	pipeline.Jobs = Jobs{
+		&Job{Name: "a"},
+	}
+
+	prj := &Project{
+		Pipelines: Pipelines{pipeline},
+	}
+
+	target := &testPipelineTarget{
+		configs: make(map[PipelineName][]byte),
+	}
+
+	require.NoError(t, prj.Deploy("team", "installation", target))
+	assert.Equal(t, 1, target.sets)
+
+	require.NoError(t, prj.Deploy("team", "installation", target))
+	assert.Equal(t, 1, target.sets)
+
+	pipeline.Jobs = append(pipeline.Jobs, &Job{Name: "b"})
+
+	require.NoError(t, prj.Deploy("team", "installation", target))
+	assert.Equal(t, 2, target.sets)
+}
+
+func TestProjectRenderConcurrently(t *testing.T) {
+	git := &Resource{Name: "git", Type: "graph-test", Source: &testSource{Id: "shared-git"}}
+	image := &Resource{Name: "image", Type: "graph-test", Source: &testSource{Id: "shared-image"}}
+
+	gitTrigger := &JobResource{Name: "git", Trigger: true}
+	gitNoTrigger := &JobResource{Name: "git", PreferredPath: "source"}
+
+	shared := &Job{
+		Name: "image",
+		Steps: ISteps{
+			&testStep{inputs: JobResources{gitNoTrigger, gitTrigger}, output: image},
+		},
+	}
+	image.NeedJobs(shared)
+
+	prj := &Project{}
+	for i := 0; i < 20; i++ {
+		pipeline := NewPipeline()
+		pipeline.Name = PipelineName(fmt.Sprintf("branch-%d", i))
+		pipeline.ResourceRegistry.MustRegister(git)
+		pipeline.Jobs = Jobs{
+			&Job{
+				Name: "test",
+				Steps: ISteps{
+					&testStep{inputs: JobResources{pipeline.ResourceRegistry.JobResource(image, true, nil)}},
+				},
+			},
+		}
+		prj.Pipelines = append(prj.Pipelines, pipeline)
+	}
+
+	prj.RenderWorkers = 1
+	sequential, err := prj.Render("team", "installation")
+	require.NoError(t, err)
+
+	prj.RenderWorkers = 8
+	concurrent, err := prj.Render("team", "installation")
+	require.NoError(t, err)
+
+	require.Len(t, concurrent, len(prj.Pipelines))
+	for i := range sequential {
+		assert.Equal(t, prj.Pipelines[i].Name, concurrent[i].Name)
+		assert.Equal(t, sequential[i].Hash, concurrent[i].Hash)
+	}
+
+	assert.Empty(t, shared.AfterJobs)
+	assert.Empty(t, prj.Pipelines[0].Jobs[0].AfterJobs)
+	assert.False(t, gitNoTrigger.Trigger)
+	assert.Equal(t, "", gitTrigger.PreferredPath)
+}
@@ -0,0 +1,221 @@
+package project
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func saveToString(t *testing.T, pipeline *Pipeline) string {
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	return yml.String()
+}
+
+func TestRenderDoesNotChangeTheProject(t *testing.T) {
+	gitSource := &testSource{Id: "render-git"}
+	image := &Resource{Name: "image", Type: "graph-test", Source: &testSource{Id: "render-image"}}
+
+	build := &Job{
+		Name: "build",
+		Steps: ISteps{
+			&testStep{inputs: JobResources{{Name: "git", Trigger: true}}, output: image},
+		},
+	}
+	image.NeedJobs(build)
+
+	newPipeline := func(name PipelineName) (*Pipeline, *Resource) {
+		pipeline := NewPipeline()
+		pipeline.Name = name
+
+		git := &Resource{Name: "git", Type: "graph-test", Source: gitSource}
+		pipeline.ResourceRegistry.MustRegister(git)
+
+		// The same content as git, it is rendered as git
+		alias := &Resource{Name: "source", Type: "graph-test", Source: gitSource}
+
+		pipeline.Jobs = Jobs{
+			&Job{
+				Name: "test",
+				Steps: ISteps{
+					&testStep{
+						inputs: JobResources{
+							pipeline.ResourceRegistry.JobResource(image, true, nil),
+							pipeline.ResourceRegistry.JobResource(alias, false, nil),
+						},
+					},
+				},
+			},
+		}
+		return pipeline, alias
+	}
+
+	first, alias := newPipeline("first")
+	second, _ := newPipeline("second")
+
+	rendered := saveToString(t, first)
+	assert.Equal(t, rendered, saveToString(t, first))
+	assert.Equal(t, rendered, saveToString(t, second))
+
+	assert.Equal(t, ResourceName("source"), alias.Name)
+	assert.Equal(t, ResourceName("git"), first.ResourceRegistry.CanonicalName("source"))
+	assert.Empty(t, build.AfterJobs)
+	assert.Empty(t, first.Jobs[0].AfterJobs)
+
+	pipeline := struct {
+		Resources []struct {
+			Name string
+		}
+	}{}
+	require.NoError(t, yaml.Unmarshal([]byte(rendered), &pipeline))
+	require.Len(t, pipeline.Resources, 2)
+	assert.Equal(t, "git", pipeline.Resources[0].Name)
+	assert.Equal(t, "image", pipeline.Resources[1].Name)
+}
+
+func TestFilterDoesNotChangeTheJobs(t *testing.T) {
+	pipeline := testGraphPipeline()
+	jobs := pipeline.Jobs
+
+	prj := &Project{
+		Pipelines: Pipelines{pipeline},
+	}
+	require.NoError(t, prj.Filter(regexp.MustCompile("^build$")))
+	assert.Equal(t, jobs, pipeline.Jobs)
+
+	filtered := saveToString(t, pipeline)
+	assert.Equal(t, filtered, saveToString(t, pipeline))
+	assert.Contains(t, filtered, "name: build")
+	assert.NotContains(t, filtered, "name: test")
+
+	pipeline.JobFilter = nil
+	assert.Contains(t, saveToString(t, pipeline), "name: test")
+}
+
+type testInvalidSource struct {
+	Id string
+}
+
+func (ts *testInvalidSource) ModelSource(scope Scope, info *ScopeInfo) interface{} {
+	return ts
+}
+
+func (ts *testInvalidSource) Validate() error {
+	return errors.New("Source is misconfigured")
+}
+
+func TestRenderValidatesSources(t *testing.T) {
+	pipeline := NewPipeline()
+	pipeline.Name = "invalid"
+
+	invalid := &Resource{Name: "invalid", Type: "graph-test", Source: &testInvalidSource{Id: "invalid"}}
+	pipeline.Jobs = Jobs{
+		&Job{
+			Name: "test",
+			Steps: ISteps{
+				&testStep{inputs: JobResources{pipeline.ResourceRegistry.JobResource(invalid, true, nil)}},
+			},
+		},
+	}
+
+	err := pipeline.Save("team", "installation", &bytes.Buffer{})
+	assert.EqualError(t, err, "Resource invalid: Source is misconfigured")
+}
+
+func TestRenderUnregisteredResource(t *testing.T) {
+	reused := NewPipeline()
+	reused.Name = "builder"
+	tools := &Resource{Name: "tools", Type: "graph-test", Source: &testSource{Id: "unregistered-tools"}}
+	tools.NeedJobs(&Job{Name: "tools-image"})
+	toolsInput := reused.ResourceRegistry.JobResource(tools, true, nil)
+
+	pipeline := NewPipeline()
+	pipeline.Name = "main"
+	pipeline.ReuseFromPipeline = Pipelines{reused}
+	pipeline.Jobs = Jobs{
+		&Job{
+			Name: "test",
+			Steps: ISteps{
+				&testStep{inputs: JobResources{toolsInput}},
+			},
+		},
+	}
+
+	// Registered in the reused pipeline only
+	err := pipeline.Save("team", "installation", &bytes.Buffer{})
+	assert.EqualError(t, err, "Job test uses resource tools, which is not registered in pipeline main")
+
+	// Registered in both, the job producing it is not rendered
+	pipeline.ResourceRegistry.MustRegister(tools)
+	rendered := saveToString(t, pipeline)
+	assert.Contains(t, rendered, "- name: tools\n")
+	assert.NotContains(t, rendered, "tools-image")
+
+	pipeline.Jobs[0].Steps = ISteps{&testStep{inputs: JobResources{{Name: "missing"}}}}
+	err = pipeline.Save("team", "installation", &bytes.Buffer{})
+	assert.EqualError(t, err, "Job test uses resource missing, which is not registered in pipeline main")
+}
+
+func TestRenderScheduledJob(t *testing.T) {
+	pipeline := NewPipeline()
+	pipeline.Name = "scheduled"
+
+	nightly := &Job{
+		Name:  "nightly",
+		Steps: ISteps{&testStep{}},
+	}
+	nightly.RunOnSchedule(pipeline.ResourceRegistry, &Resource{
+		Name:   "nightly-schedule",
+		Type:   "graph-test",
+		Source: &testSource{Id: "2:00 AM"},
+	})
+	pipeline.Jobs = Jobs{nightly}
+
+	rendered := saveToString(t, pipeline)
+	assert.Contains(t, rendered, "- name: nightly-schedule\n")
+	assert.Contains(t, rendered, "- get: nightly-schedule\n    trigger: true\n")
+}
+
+func TestRenderPipelineFailureHook(t *testing.T) {
+	build := &Job{
+		Name:  "build",
+		Steps: ISteps{&testStep{}},
+	}
+
+	// A hook shared by the jobs
+	shared := &DoStep{Steps: ISteps{&testStep{}}}
+	build.AddOnSuccess(shared)
+
+	newPipeline := func(name PipelineName) *Pipeline {
+		pipeline := NewPipeline()
+		pipeline.Name = name
+		pipeline.Jobs = Jobs{build}
+		return pipeline
+	}
+
+	notified := newPipeline("notified")
+	alert := &Resource{Name: "alert", Type: "graph-test", Source: &testSource{Id: "alert"}}
+	hook := &testStep{inputs: JobResources{notified.ResourceRegistry.JobResource(alert, false, nil)}}
+	notified.AddOnFailure(hook)
+	notified.AddOnFailure(hook)
+	assert.Equal(t, hook, notified.OnFailure)
+
+	other := &Job{Name: "other", Steps: ISteps{&testStep{}}}
+	other.AddOnSuccess(shared)
+	other.AddOnSuccess(&testStep{output: alert})
+	assert.Len(t, shared.Steps, 1)
+
+	rendered := saveToString(t, notified)
+	assert.Contains(t, rendered, "- name: alert\n")
+	assert.Contains(t, rendered, "  on_failure:\n    task: \"\"\n")
+	assert.Nil(t, build.OnFailure)
+
+	plain := saveToString(t, newPipeline("plain"))
+	assert.NotContains(t, plain, "alert")
+	assert.NotContains(t, plain, "on_failure")
+}
@@ -1,13 +1,7 @@
 package project
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"fmt"
-	"strings"
-
 	"github.com/concourse-friends/concourse-builder/model"
-	"gopkg.in/yaml.v2"
 )
 
 type IInputResource interface {
@@ -27,6 +21,9 @@ type Resource struct {
 	// The scope of the resource
 	Scope Scope
 
+	// Overrides the components the scope prefixes the scoped names of the source with
+	Naming ScopeComponents
+
 	// On what interval the resource to be pooled for updates
 	CheckInterval model.Duration
 
@@ -44,25 +41,8 @@ func (r *Resource) NeededJobs() Jobs {
 	if registryType.Source == nil {
 		return r.neededJobs
 	}
-	neededJobs := registryType.Source.NeededJobs()
+	neededJobs := append(Jobs(nil), registryType.Source.NeededJobs()...)
 	return append(neededJobs, r.neededJobs...)
 }
 
 type Resources []Resource
-
-type ResourceHash string
-
-func (r *Resource) MustHash() ResourceHash {
-	yml, err := yaml.Marshal(r)
-	if err != nil {
-		panic(err.Error())
-	}
-
-	str := strings.Replace(string(yml), fmt.Sprintf("name: %s", r.Name), "@@@", 1)
-
-	sha := sha256.New()
-	sha.Write([]byte(str))
-	hash := sha.Sum(nil)
-
-	return ResourceHash(hex.EncodeToString(hash))
-}
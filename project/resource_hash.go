@@ -0,0 +1,118 @@
+package project
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Identifies the content of a resource, resources with the same hash are the same resource
+type ResourceHash string
+
+// The version of the resource hashing scheme. It is part of every hash, so hashes of
+// different schemes never match. Increment it whenever the scheme below changes.
+//
+// Version 1: sha256 of the compact json with sorted keys of
+//
+//	{"scope": <Scope>, "source": <Source>, "type": <Type>, "version": 1}
+//
+// The source is marshalled as yaml, the way it is rendered, and its empty values (null, "", false,
+// 0, empty lists and maps) are pruned, so adding fields to a source type does not change the hashes.
+// The naming override is added as "naming" only when it is set, so it does not change the other hashes.
+// The name and the check interval are not part of the hash.
+const ResourceHashVersion = 1
+
+// Removes the empty values, returns false if the value itself is empty
+func pruneValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return v, v != ""
+	case bool:
+		return v, v
+	case int:
+		return v, v != 0
+	case float64:
+		return v, v != 0
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if pruned, ok := pruneValue(item); ok {
+				result[key] = pruned
+			}
+		}
+		return result, len(result) > 0
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			pruned, ok := pruneValue(item)
+			if !ok {
+				pruned = nil
+			}
+			result = append(result, pruned)
+		}
+		return result, len(result) > 0
+	}
+	return value, true
+}
+
+func canonicalSource(source IJobResourceSource) (interface{}, error) {
+	if source == nil {
+		return nil, nil
+	}
+
+	yml, err := yaml.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err = yaml.Unmarshal(yml, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned, _ := pruneValue(canonicalValue(value))
+	return pruned, nil
+}
+
+// Hashes the content of the resource with the scheme of ResourceHashVersion
+func (r *Resource) Hash() (ResourceHash, error) {
+	source, err := canonicalSource(r.Source)
+	if err != nil {
+		return "", err
+	}
+
+	canonical := &bytes.Buffer{}
+	encoder := json.NewEncoder(canonical)
+	encoder.SetEscapeHTML(false)
+	content := map[string]interface{}{
+		"version": ResourceHashVersion,
+		"type":    r.Type,
+		"scope":   r.Scope,
+		"source":  source,
+	}
+	if r.Naming != nil {
+		content["naming"] = r.Naming
+	}
+
+	err = encoder.Encode(content)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(canonical.Bytes())
+	return ResourceHash(hex.EncodeToString(hash[:])), nil
+}
+
+func (r *Resource) MustHash() ResourceHash {
+	hash, err := r.Hash()
+	if err != nil {
+		panic(err.Error())
+	}
+	return hash
+}
@@ -0,0 +1,95 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSourceV2 struct {
+	Id     string
+	Branch string
+	Paths  []string
+	Config map[string]interface{}
+}
+
+func (ts *testSourceV2) ModelSource(scope Scope, info *ScopeInfo) interface{} {
+	return ts
+}
+
+func TestResourceHashStable(t *testing.T) {
+	resource := &Resource{
+		Name:   "git",
+		Type:   "git",
+		Source: &testSource{Id: "repo"},
+	}
+
+	// Changing this hash breaks the reuse of resources between pipelines of different versions,
+	// change ResourceHashVersion together with it
+	assert.Equal(t, ResourceHash("1bddf0664b6037ba9420d2f7153168936904a70305fd0d0433b42ba6e464ff28"), resource.MustHash())
+}
+
+func TestResourceHashIgnoresNameAndCheckInterval(t *testing.T) {
+	a := &Resource{
+		Name:   "a",
+		Type:   "git",
+		Source: &testSource{Id: "name: a"},
+	}
+	b := &Resource{
+		Name:          "name: a",
+		Type:          "git",
+		Source:        &testSource{Id: "name: a"},
+		CheckInterval: model.Duration(time.Hour),
+	}
+
+	assert.Equal(t, a.MustHash(), b.MustHash())
+}
+
+func TestResourceHashCollisions(t *testing.T) {
+	resources := []*Resource{
+		{Name: "a", Type: "git", Source: &testSource{Id: "a"}},
+		{Name: "a", Type: "git", Source: &testSource{Id: "b"}},
+		{Name: "a", Type: "s3", Source: &testSource{Id: "a"}},
+		{Name: "a", Type: "git", Source: &testSource{Id: "a"}, Scope: TeamScope},
+		{Name: "a", Type: "git"},
+		{Name: "a", Type: "git", Source: &testSourceV2{Id: "a", Paths: []string{"x", "y"}}},
+		{Name: "a", Type: "git", Source: &testSourceV2{Id: "a", Paths: []string{"y", "x"}}},
+		// The name of the resource inside the source must not be confused with the name
+		{Name: "a", Type: "git", Source: &testSource{Id: "name: a"}},
+	}
+
+	hashes := make(map[ResourceHash]int)
+	for i, resource := range resources {
+		hash := resource.MustHash()
+		if j, ok := hashes[hash]; ok {
+			t.Errorf("Resources %d and %d have the same hash", j, i)
+		}
+		hashes[hash] = i
+	}
+}
+
+func TestResourceHashIgnoresEmptyFields(t *testing.T) {
+	v1 := &Resource{
+		Name:   "a",
+		Type:   "git",
+		Source: &testSource{Id: "a"},
+	}
+	v2 := &Resource{
+		Name:   "a",
+		Type:   "git",
+		Source: &testSourceV2{Id: "a", Config: map[string]interface{}{}},
+	}
+	assert.Equal(t, v1.MustHash(), v2.MustHash())
+
+	ordered := &Resource{
+		Type:   "git",
+		Source: &testSourceV2{Config: map[string]interface{}{"a": 1, "b": 2}},
+	}
+	reordered := &Resource{
+		Type:   "git",
+		Source: &testSourceV2{Config: map[string]interface{}{"b": 2, "a": 1}},
+	}
+	assert.Equal(t, ordered.MustHash(), reordered.MustHash())
+}
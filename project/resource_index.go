@@ -0,0 +1,260 @@
+package project
+
+import (
+	"sort"
+
+	"github.com/concourse-friends/concourse-builder/model"
+)
+
+// Index of the resources of the jobs of one pipeline render.
+// It is built once, so the resources of a job are collected only once per render.
+type ResourceIndex struct {
+	jobs Jobs
+
+	// The jobs that are rendered in the pipeline
+	pipelineJobs JobsSet
+
+	// All resources of each job, inputs and outputs
+	jobResources map[*Job]JobResources
+
+	// The input resources of each job
+	jobInputs map[*Job]JobResources
+
+	// The jobs that use each resource
+	users map[ResourceName]JobsSet
+
+	// The jobs that put each resource
+	producers map[ResourceName]Jobs
+
+	// The jobs that get each resource
+	consumers map[ResourceName]Jobs
+
+	// All resources of all jobs
+	resources JobResources
+
+	// The order of the jobs of the render
+	order jobOrder
+
+	// Resolves the names the resources are rendered with, nil if the names are used as they are
+	registry *ResourceRegistry
+
+	// Cache of the jobs each job runs after, directly or through other jobs
+	upstream map[*Job]JobsSet
+
+	// The scope the pipeline is rendered in, nil if unknown
+	info *ScopeInfo
+
+	// The step run when any of the jobs fails, after the failure hook of the job
+	onFailure IStep
+}
+
+// Indexes the jobs ordered only by their AddJobToRunAfter declarations
+func NewResourceIndex(jobs Jobs) (*ResourceIndex, error) {
+	return newResourceIndex(jobs, explicitJobOrder(jobs), nil, nil)
+}
+
+func newResourceIndex(jobs Jobs, order jobOrder, registry *ResourceRegistry, onFailure IStep) (*ResourceIndex, error) {
+	sorted := make(Jobs, len(jobs))
+	copy(sorted, jobs)
+	sort.Sort(sorted)
+
+	index := &ResourceIndex{
+		jobs:         sorted,
+		pipelineJobs: sorted.Set(),
+		jobResources: make(map[*Job]JobResources, len(jobs)),
+		jobInputs:    make(map[*Job]JobResources, len(jobs)),
+		users:        make(map[ResourceName]JobsSet),
+		producers:    make(map[ResourceName]Jobs),
+		consumers:    make(map[ResourceName]Jobs),
+		order:        order,
+		registry:     registry,
+		upstream:     make(map[*Job]JobsSet),
+		onFailure:    onFailure,
+	}
+
+	var allResources JobResources
+	for _, job := range sorted {
+		hooked := job.withPipelineHook(onFailure)
+
+		inputs, err := hooked.InputResources()
+		if err != nil {
+			return nil, err
+		}
+		inputs = index.canonicalResources(inputs)
+		index.jobInputs[job] = inputs
+
+		resources, err := hooked.Resources()
+		if err != nil {
+			return nil, err
+		}
+		resources = index.canonicalResources(resources)
+		index.jobResources[job] = resources
+		allResources = append(allResources, resources...)
+
+		for _, resource := range resources {
+			if index.users[resource.Name] == nil {
+				index.users[resource.Name] = make(JobsSet)
+			}
+			index.users[resource.Name][job] = struct{}{}
+		}
+
+		for _, input := range inputs {
+			index.consumers[input.Name] = append(index.consumers[input.Name], job)
+		}
+
+		outputs, err := hooked.OutputResources()
+		if err != nil {
+			return nil, err
+		}
+		for _, output := range index.canonicalResources(outputs) {
+			index.producers[output.Name] = append(index.producers[output.Name], job)
+		}
+	}
+
+	index.resources = allResources.Deduplicate()
+
+	return index, nil
+}
+
+// The name the resource is rendered with
+func (ri *ResourceIndex) CanonicalName(name ResourceName) ResourceName {
+	if ri.registry == nil {
+		return name
+	}
+	return ri.registry.CanonicalName(name)
+}
+
+// Copies of the resources renamed to their canonical names, merged if they end up with the same name
+func (ri *ResourceIndex) canonicalResources(resources JobResources) JobResources {
+	renamed := false
+	canonical := make(JobResources, 0, len(resources))
+	for _, resource := range resources {
+		name := ri.CanonicalName(resource.Name)
+		if name != resource.Name {
+			copied := *resource
+			copied.Name = name
+			resource = &copied
+			renamed = true
+		}
+		canonical = append(canonical, resource)
+	}
+
+	if !renamed {
+		return resources
+	}
+	return canonical.Deduplicate()
+}
+
+// Overrides the trigger of the get steps of the resource
+func (ri *ResourceIndex) setTrigger(name ResourceName, trigger bool) {
+	for job, inputs := range ri.jobInputs {
+		overridden := make(JobResources, 0, len(inputs))
+		for _, input := range inputs {
+			if input.Name == name && input.Trigger != trigger {
+				copied := *input
+				copied.Trigger = trigger
+				input = &copied
+			}
+			overridden = append(overridden, input)
+		}
+		ri.jobInputs[job] = overridden
+	}
+}
+
+// The jobs of the index, sorted by name
+func (ri *ResourceIndex) Jobs() Jobs {
+	return ri.jobs
+}
+
+// Whether the job is part of the index
+func (ri *ResourceIndex) Contains(job *Job) bool {
+	_, ok := ri.pipelineJobs[job]
+	return ok
+}
+
+// All resources of all jobs
+func (ri *ResourceIndex) Resources() JobResources {
+	return ri.resources
+}
+
+// All resources of the job, inputs and outputs
+func (ri *ResourceIndex) JobResources(job *Job) JobResources {
+	return ri.jobResources[job]
+}
+
+// The input resources of the job
+func (ri *ResourceIndex) Inputs(job *Job) JobResources {
+	return ri.jobInputs[job]
+}
+
+// Whether the job gets or puts the resource
+func (ri *ResourceIndex) Uses(job *Job, name ResourceName) bool {
+	_, ok := ri.users[name][job]
+	return ok
+}
+
+// The jobs that put the resource, sorted by name
+func (ri *ResourceIndex) Producers(name ResourceName) Jobs {
+	return ri.producers[name]
+}
+
+// The jobs that get the resource, sorted by name
+func (ri *ResourceIndex) Consumers(name ResourceName) Jobs {
+	return ri.consumers[name]
+}
+
+// Sorts the jobs in columns by the order of the render
+func (ri *ResourceIndex) SortByColumns() ([]Jobs, error) {
+	return ri.jobs.sortByColumns(ri.order)
+}
+
+// The after jobs of the job that are part of the index, sorted by name
+func (ri *ResourceIndex) AfterJobs(job *Job) Jobs {
+	var afterJobs Jobs
+	for afterJob := range ri.order[job] {
+		if ri.Contains(afterJob) {
+			afterJobs = append(afterJobs, afterJob)
+		}
+	}
+	sort.Sort(afterJobs)
+	return afterJobs
+}
+
+// All jobs of the index the job runs after, directly or through other jobs
+func (ri *ResourceIndex) Upstream(job *Job) JobsSet {
+	if upstream, ok := ri.upstream[job]; ok {
+		return upstream
+	}
+
+	upstream := make(JobsSet)
+	// Protects from cycles, they are reported when the jobs are sorted
+	ri.upstream[job] = upstream
+
+	for _, afterJob := range ri.AfterJobs(job) {
+		upstream[afterJob] = struct{}{}
+		for upstreamJob := range ri.Upstream(afterJob) {
+			upstream[upstreamJob] = struct{}{}
+		}
+	}
+
+	return upstream
+}
+
+// Puts the resource with its canonical name, the put steps refer to the resources they were built with
+func (ri *ResourceIndex) canonicalPut(step model.IStep) model.IStep {
+	switch step := step.(type) {
+	case *model.Put:
+		step.Put = model.ResourceName(ri.CanonicalName(ResourceName(step.Put)))
+	case *model.Aggregation:
+		for _, aggregated := range step.Aggregate {
+			ri.canonicalPut(aggregated)
+		}
+	case *model.Do:
+		for _, done := range step.Do {
+			ri.canonicalPut(done)
+		}
+	case *model.Try:
+		ri.canonicalPut(step.Try)
+	}
+	return step
+}
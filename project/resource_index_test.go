@@ -0,0 +1,145 @@
+package project
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A pipeline with a git resource and layers of jobs, every job of a layer
+// consumes the image produced by a job of the previous layer
+func benchmarkPipeline(layers, width int) *Pipeline {
+	pipeline := NewPipeline()
+	pipeline.Name = "benchmark"
+
+	git := &Resource{Name: "git", Type: "graph-test", Source: &testSource{Id: "benchmark-git"}}
+
+	var previous []*Resource
+	for l := 0; l < layers; l++ {
+		var current []*Resource
+		for w := 0; w < width; w++ {
+			name := fmt.Sprintf("image-%d-%d", l, w)
+			image := &Resource{Name: ResourceName(name), Type: "graph-test", Source: &testSource{Id: name}}
+			pipeline.ResourceRegistry.MustRegister(image)
+
+			inputs := JobResources{pipeline.ResourceRegistry.JobResource(git, true, nil)}
+			if previous != nil {
+				inputs = append(inputs, pipeline.ResourceRegistry.JobResource(previous[w], true, nil))
+			}
+
+			job := &Job{
+				Name: JobName(name),
+				Steps: ISteps{
+					&testStep{inputs: inputs, output: image},
+				},
+			}
+			image.NeedJobs(job)
+
+			current = append(current, image)
+			if l == layers-1 {
+				pipeline.Jobs = append(pipeline.Jobs, job)
+			}
+		}
+		previous = current
+	}
+
+	return pipeline
+}
+
+func TestResourceIndex(t *testing.T) {
+	pipeline := benchmarkPipeline(2, 2)
+
+	index, err := pipeline.resourceIndex()
+	require.NoError(t, err)
+
+	jobs := make(map[JobName]*Job)
+	for _, job := range index.Jobs() {
+		jobs[job.Name] = job
+	}
+
+	assert.Len(t, index.Resources(), 5)
+	assert.Equal(t, Jobs{jobs["image-0-1"]}, index.Producers("image-0-1"))
+	assert.Equal(t, Jobs{jobs["image-1-1"]}, index.Consumers("image-0-1"))
+	assert.Len(t, index.Consumers("git"), 4)
+	assert.True(t, index.Uses(jobs["image-1-0"], "image-0-0"))
+	assert.False(t, index.Uses(jobs["image-1-0"], "image-0-1"))
+
+	inputs := index.Inputs(jobs["image-1-0"])
+	require.Len(t, inputs, 2)
+	assert.Equal(t, ResourceName("git"), inputs[0].Name)
+	assert.Equal(t, ResourceName("image-0-0"), inputs[1].Name)
+
+	assert.Equal(t, JobsSet{jobs["image-0-0"]: struct{}{}}, index.Upstream(jobs["image-1-0"]))
+}
+
+func benchmarkSave(b *testing.B, layers, width int) {
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for i := 0; i < b.N; i++ {
+		pipeline := benchmarkPipeline(layers, width)
+		err := pipeline.Save("team", "installation", ioutil.Discard)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSaveSmall(b *testing.B) {
+	benchmarkSave(b, 5, 5)
+}
+
+func BenchmarkSaveDeep(b *testing.B) {
+	benchmarkSave(b, 50, 2)
+}
+
+func BenchmarkSaveWide(b *testing.B) {
+	benchmarkSave(b, 5, 50)
+}
+
+func TestCompositeStepOutputs(t *testing.T) {
+	pipeline := NewPipeline()
+	pipeline.Name = "composite"
+
+	newResource := func(name ResourceName) *Resource {
+		resource := &Resource{Name: name, Type: "graph-test", Source: &testSource{Id: "composite-" + string(name)}}
+		pipeline.ResourceRegistry.MustRegister(resource)
+		return resource
+	}
+
+	first := newResource("first")
+	second := newResource("second")
+	third := newResource("third")
+
+	// The same content as third, it is put as third
+	alias := &Resource{Name: "alias", Type: "graph-test", Source: &testSource{Id: "composite-third"}}
+	pipeline.ResourceRegistry.MustRegister(alias)
+
+	job := &Job{
+		Name: "publish",
+		Steps: ISteps{
+			&DoStep{Steps: ISteps{&PutStep{Resource: first}, &PutStep{Resource: second}}},
+			&AggregateStep{Steps: ISteps{&testStep{}, &PutStep{Resource: alias}}},
+		},
+	}
+	pipeline.Jobs = Jobs{job}
+
+	outputs, err := job.OutputResources()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, JobResources{{Name: "first"}, {Name: "second"}, {Name: "alias"}}, outputs)
+
+	index, err := pipeline.resourceIndex()
+	require.NoError(t, err)
+	for _, resource := range []*Resource{first, second, third} {
+		assert.Equal(t, Jobs{job}, index.Producers(resource.Name), string(resource.Name))
+	}
+
+	rendered := saveToString(t, pipeline)
+	assert.Contains(t, rendered, "  - aggregate:\n    - task: \"\"\n    - put: third\n")
+	assert.NotContains(t, rendered, "alias")
+}
@@ -1,56 +1,232 @@
 package project
 
-import "fmt"
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// What the registry does when a registered resource conflicts with an already registered one
+type ConflictPolicy int
+
+const (
+	// Resources with the same content are rendered as the first registered one.
+	// A name registered again with different content keeps its first content.
+	ConflictMerge ConflictPolicy = iota
+
+	// Any alias or name registered again with different content is an error
+	ConflictError
+
+	// Resources with the same content but different names are rendered separately.
+	// A name registered again with different content is an error.
+	ConflictKeepBoth
+)
+
+type RegistryEventKind int
+
+const (
+	// A resource with the same content as a registered one is rendered as the registered one
+	AliasEvent RegistryEventKind = iota
+
+	// A resource with the same content as a registered one is rendered separately
+	KeepBothEvent
+
+	// A name registered again with different content kept its first content
+	MergeEvent
+)
+
+// Something the registry decided about a conflicting resource
+type RegistryEvent struct {
+	Kind RegistryEventKind
+
+	// The name of the registered resource
+	Name ResourceName
+
+	// The name of the resource it conflicted with
+	Registered ResourceName
+}
+
+func (re *RegistryEvent) String() string {
+	switch re.Kind {
+	case AliasEvent:
+		return fmt.Sprintf("alias %s: rendered as %s, they have the same content", re.Name, re.Registered)
+	case KeepBothEvent:
+		return fmt.Sprintf("keep %s: rendered separately from %s, they have the same content", re.Name, re.Registered)
+	default:
+		return fmt.Sprintf("merge %s: registered again with different content, the first content is kept", re.Name)
+	}
+}
+
+type RegistryEvents []*RegistryEvent
+
+func (re RegistryEvents) String() string {
+	lines := make([]string, 0, len(re))
+	for _, event := range re {
+		lines = append(lines, event.String())
+	}
+	return strings.Join(lines, "\n")
+}
 
 // An object that tracks collection of resources by name
 type ResourceRegistry struct {
-	cross     map[ResourceName]ResourceHash
+	// How the conflicting resources are handled
+	Policy ConflictPolicy
+
+	lock sync.RWMutex
+
+	// The resource each name is rendered as
+	names map[ResourceName]*Resource
+
+	// The first resource registered with each content
 	resources map[ResourceHash]*Resource
+
+	// The names rendered as another resource whatever is registered with them
+	substitutes map[ResourceName]struct{}
+
+	events RegistryEvents
 }
 
 type ResourceRegistries []*ResourceRegistry
 
 func NewResourceRegistry() *ResourceRegistry {
 	return &ResourceRegistry{
-		cross:     make(map[ResourceName]ResourceHash),
-		resources: make(map[ResourceHash]*Resource),
+		names:       make(map[ResourceName]*Resource),
+		resources:   make(map[ResourceHash]*Resource),
+		substitutes: make(map[ResourceName]struct{}),
 	}
 }
 
-func (rr *ResourceRegistry) MustRegister(resource *Resource) {
-	if hash, ok := rr.cross[resource.Name]; ok {
-		resource.Name = rr.resources[hash].Name
-		return
+// Registers the resource. The resource is not changed, what it is rendered as depends on the policy.
+func (rr *ResourceRegistry) Register(resource *Resource) error {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+
+	if _, ok := rr.substitutes[resource.Name]; ok {
+		return nil
+	}
+
+	if registered, ok := rr.names[resource.Name]; ok {
+		if registered == resource || registered.MustHash() == resource.MustHash() {
+			return nil
+		}
+
+		if rr.Policy != ConflictMerge {
+			return fmt.Errorf("Resource %s is already registered with different content", resource.Name)
+		}
+
+		rr.addEvent(&RegistryEvent{
+			Kind:       MergeEvent,
+			Name:       resource.Name,
+			Registered: registered.Name,
+		})
+		return nil
 	}
 
 	hash := resource.MustHash()
-	rr.cross[resource.Name] = hash
 
-	if res, ok := rr.resources[hash]; ok {
-		resource.Name = res.Name
-		return
+	registered, ok := rr.resources[hash]
+	if !ok {
+		rr.resources[hash] = resource
+		rr.names[resource.Name] = resource
+		return nil
+	}
+
+	switch rr.Policy {
+	case ConflictError:
+		return fmt.Errorf("Resource %s has the same content as the registered resource %s",
+			resource.Name, registered.Name)
+	case ConflictKeepBoth:
+		rr.names[resource.Name] = resource
+		rr.addEvent(&RegistryEvent{
+			Kind:       KeepBothEvent,
+			Name:       resource.Name,
+			Registered: registered.Name,
+		})
+	default:
+		rr.names[resource.Name] = registered
+		rr.addEvent(&RegistryEvent{
+			Kind:       AliasEvent,
+			Name:       resource.Name,
+			Registered: registered.Name,
+		})
 	}
 
-	rr.resources[hash] = resource
+	return nil
+}
+
+// Renders the name as the resource. The resources registered with the name, before or after, are
+// rendered as the substitute, e.g. the pull requests in place of the git branch the jobs are built with.
+func (rr *ResourceRegistry) Substitute(name ResourceName, resource *Resource) error {
+	err := rr.Register(resource)
+	if err != nil {
+		return err
+	}
+
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+
+	rr.names[name] = rr.names[resource.Name]
+	rr.substitutes[name] = struct{}{}
+	return nil
+}
+
+func (rr *ResourceRegistry) addEvent(event *RegistryEvent) {
+	for _, e := range rr.events {
+		if *e == *event {
+			return
+		}
+	}
+	log.Printf("Resource registry %s", event)
+	rr.events = append(rr.events, event)
+}
+
+func (rr *ResourceRegistry) MustRegister(resource *Resource) {
+	err := rr.Register(resource)
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+// Every alias and merge the registry did, in the order they happened
+func (rr *ResourceRegistry) Report() RegistryEvents {
+	rr.lock.RLock()
+	defer rr.lock.RUnlock()
+
+	return append(RegistryEvents(nil), rr.events...)
+}
+
+// The name a resource is rendered with. Unknown names are returned as they are.
+func (rr *ResourceRegistry) CanonicalName(name ResourceName) ResourceName {
+	rr.lock.RLock()
+	defer rr.lock.RUnlock()
+
+	if res, ok := rr.names[name]; ok {
+		return res.Name
+	}
+	return name
 }
 
 func (rr *ResourceRegistry) JobResource(resource *Resource, trigger bool, getParams interface{}) *JobResource {
 	rr.MustRegister(resource)
 	return &JobResource{
-		Name:      resource.Name,
+		Name:      rr.CanonicalName(resource.Name),
 		Trigger:   trigger,
 		GetParams: getParams,
 	}
 }
 
 func (rr *ResourceRegistry) GetResource(name ResourceName) *Resource {
-	if hash, ok := rr.cross[name]; ok {
-		return rr.GetResourceByHash(hash)
-	}
-	return nil
+	rr.lock.RLock()
+	defer rr.lock.RUnlock()
+
+	return rr.names[name]
 }
 
 func (rr *ResourceRegistry) GetResourceByHash(hash ResourceHash) *Resource {
+	rr.lock.RLock()
+	defer rr.lock.RUnlock()
+
 	if res, ok := rr.resources[hash]; ok {
 		return res
 	}
@@ -58,7 +234,10 @@ func (rr *ResourceRegistry) GetResourceByHash(hash ResourceHash) *Resource {
 }
 
 func (rr *ResourceRegistry) MustGetResource(name ResourceName) *Resource {
-	res := rr.resources[rr.cross[name]]
+	rr.lock.RLock()
+	defer rr.lock.RUnlock()
+
+	res := rr.names[name]
 	if res == nil {
 		panic(fmt.Sprintf("Resource %s not found", name))
 	}
@@ -20,3 +20,85 @@ func TestResourceRegistry(t *testing.T) {
 
 	assert.Equal(t, resourceFoo.MustHash(), resourceBar.MustHash())
 }
+
+func testConflictingResources() (*Resource, *Resource, *Resource) {
+	git := &Resource{
+		Name:   "git",
+		Type:   "graph-test",
+		Source: &testSource{Id: "conflict-git"},
+	}
+	gitNightly := &Resource{
+		Name:   "git-nightly",
+		Type:   "graph-test",
+		Source: &testSource{Id: "conflict-git"},
+	}
+	gitOther := &Resource{
+		Name:   "git",
+		Type:   "graph-test",
+		Source: &testSource{Id: "conflict-other"},
+	}
+	return git, gitNightly, gitOther
+}
+
+func TestResourceRegistryConflictMerge(t *testing.T) {
+	git, gitNightly, gitOther := testConflictingResources()
+
+	registry := NewResourceRegistry()
+	registry.MustRegister(git)
+	registry.MustRegister(gitNightly)
+	registry.MustRegister(gitNightly)
+	registry.MustRegister(gitOther)
+
+	assert.Equal(t, ResourceName("git"), registry.CanonicalName("git-nightly"))
+	assert.Equal(t, git, registry.MustGetResource("git"))
+	assert.Equal(t, ResourceName("git-nightly"), gitNightly.Name)
+	assert.Equal(t, "alias git-nightly: rendered as git, they have the same content\n"+
+		"merge git: registered again with different content, the first content is kept",
+		registry.Report().String())
+}
+
+func TestResourceRegistryConflictError(t *testing.T) {
+	git, gitNightly, gitOther := testConflictingResources()
+
+	registry := NewResourceRegistry()
+	registry.Policy = ConflictError
+	assert.NoError(t, registry.Register(git))
+	assert.NoError(t, registry.Register(git))
+	assert.EqualError(t, registry.Register(gitNightly),
+		"Resource git-nightly has the same content as the registered resource git")
+	assert.EqualError(t, registry.Register(gitOther),
+		"Resource git is already registered with different content")
+	assert.Panics(t, func() {
+		registry.MustRegister(gitOther)
+	})
+	assert.Empty(t, registry.Report())
+}
+
+func TestResourceRegistryConflictKeepBoth(t *testing.T) {
+	git, gitNightly, gitOther := testConflictingResources()
+
+	registry := NewResourceRegistry()
+	registry.Policy = ConflictKeepBoth
+	registry.MustRegister(git)
+	registry.MustRegister(gitNightly)
+
+	assert.Equal(t, ResourceName("git-nightly"), registry.CanonicalName("git-nightly"))
+	assert.Equal(t, gitNightly, registry.MustGetResource("git-nightly"))
+	assert.Equal(t, git, registry.GetResourceByHash(git.MustHash()))
+	assert.Error(t, registry.Register(gitOther))
+	assert.Equal(t, "keep git-nightly: rendered separately from git, they have the same content",
+		registry.Report().String())
+}
+
+func TestResourceRegistrySubstitute(t *testing.T) {
+	git, _, gitOther := testConflictingResources()
+	pullRequests := &Resource{Name: "pull-requests", Type: "graph-test", Source: &testSource{Id: "pull-requests"}}
+
+	registry := NewResourceRegistry()
+	registry.Policy = ConflictError
+	assert.NoError(t, registry.Substitute(git.Name, pullRequests))
+
+	assert.Equal(t, ResourceName("pull-requests"), registry.JobResource(gitOther, true, nil).Name)
+	assert.Equal(t, pullRequests, registry.MustGetResource(git.Name))
+	assert.Empty(t, registry.Report())
+}
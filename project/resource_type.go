@@ -1,6 +1,8 @@
 package project
 
 import (
+	"fmt"
+
 	"github.com/concourse-friends/concourse-builder/model"
 )
 
@@ -32,15 +34,21 @@ func (rt *ResourceType) IsSystem() bool {
 	return string(rt.Type) == string(model.SystemResourceTypeName)
 }
 
-func (rt *ResourceType) Model(info *ScopeInfo) *model.ResourceType {
+func (rt *ResourceType) Model(info *ScopeInfo) (*model.ResourceType, error) {
 	resourceType := &model.ResourceType{
 		Name: model.ResourceTypeName(rt.Name),
 		Type: rt.Type,
 	}
 
+	if validator, ok := rt.Source.(ISourceValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, fmt.Errorf("Resource type %s: %s", rt.Name, err.Error())
+		}
+	}
+
 	if rt.Source != nil {
 		resourceType.Source = rt.Source.ModelSource(rt.Source.ResourceScope(), info)
 	}
 
-	return resourceType
+	return resourceType, nil
 }
@@ -3,16 +3,21 @@ package project
 import (
 	"bytes"
 	"fmt"
+	"sync"
 
 	"gopkg.in/yaml.v2"
 )
 
 // An object that tracks collection of resources by name
 type TypeRegistry struct {
+	lock  sync.RWMutex
 	types map[ResourceTypeName]*ResourceType
 }
 
 func (r *TypeRegistry) MustRegisterType(resourceType *ResourceType) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
 	res, ok := r.types[resourceType.Name]
 	if ok {
 		current, err := yaml.Marshal(res)
@@ -35,6 +40,9 @@ func (r *TypeRegistry) MustRegisterType(resourceType *ResourceType) {
 }
 
 func (r *TypeRegistry) RegisterType(resourceTypeName ResourceTypeName) *ResourceType {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
 	return r.types[resourceTypeName]
 }
 
@@ -1,49 +1,145 @@
 package project
 
-import "fmt"
+import "strings"
 
+// The scope of a resource is the set of pipelines that share it. Scoped sources, like image tags
+// and S3 keys, prefix their names with the components of the scope:
+//
+//	Scope                      Components                     Image tag (-)               S3 key (_)
+//	PipelineScope              none                           tag                         file
+//	AllPipelinesScope          installation, team             inst-team-tag               inst_team_file
+//	TeamScope                  pipeline                       pipeline-tag                pipeline_file
+//	AllTeamsScope              installation                   inst-tag                    inst_file
+//	InstallationScope          team, pipeline                 team-pipeline-tag           team_pipeline_file
+//	UniverseScope              installation, team, pipeline   inst-team-pipeline-tag      inst_team_pipeline_file
+//
+//	IsolatedPipelineScope      installation, team, pipeline   inst-team-pipeline-tag      inst_team_pipeline_file
+//	IsolatedTeamScope          installation, team             inst-team-tag               inst_team_file
+//	IsolatedInstallationScope  installation                   inst-tag                    inst_file
+//	PublicScope                none                           tag                         file
+//
+// The first scopes keep the prefixes they always had, the names of the deployed resources depend on them.
+// Their names do not tell which pipelines share the resource, the isolated scopes and PublicScope do
+// and are preferred for new resources.
+//
+// Empty components are skipped. Resource.Naming overrides the components of a single resource.
 type Scope int
 
 type TeamName string
 type InstallationName string
 
 const (
-	PipelineScope = iota
+	PipelineScope Scope = iota
 	AllPipelinesScope
 	TeamScope
 	AllTeamsScope
 	InstallationScope
 	UniverseScope
+
+	// Only the pipeline uses the resource
+	IsolatedPipelineScope
+
+	// All pipelines of the team share the resource
+	IsolatedTeamScope
+
+	// All teams of the installation share the resource
+	IsolatedInstallationScope
+
+	// Every installation shares the resource, public images for example
+	PublicScope
 )
 
+// A part of the name of a scoped resource
+type ScopeComponent int
+
+const (
+	InstallationComponent ScopeComponent = iota
+	TeamComponent
+	PipelineComponent
+)
+
+type ScopeComponents []ScopeComponent
+
+// No components at all. Unlike nil it overrides the components of the scope.
+var NoScopeComponents = ScopeComponents{}
+
+// The components the names of the resources of the scope are prefixed with
+func (s Scope) Components() ScopeComponents {
+	switch s {
+	case AllPipelinesScope, IsolatedTeamScope:
+		return ScopeComponents{InstallationComponent, TeamComponent}
+	case TeamScope:
+		return ScopeComponents{PipelineComponent}
+	case AllTeamsScope, IsolatedInstallationScope:
+		return ScopeComponents{InstallationComponent}
+	case InstallationScope:
+		return ScopeComponents{TeamComponent, PipelineComponent}
+	case UniverseScope, IsolatedPipelineScope:
+		return ScopeComponents{InstallationComponent, TeamComponent, PipelineComponent}
+	}
+	return NoScopeComponents
+}
+
 type ScopeInfo struct {
 	Pipeline     PipelineName
 	Team         TeamName
 	Installation InstallationName
+
+	// Overrides the components of the scope when not nil
+	Naming ScopeComponents
 }
 
-func (info *ScopeInfo) Scope(scope Scope, delimiter string) string {
-	switch scope {
-	case UniverseScope:
-		return fmt.Sprintf("%s%s%s%s%s%s",
-			info.Installation, delimiter,
-			info.Team, delimiter,
-			info.Pipeline, delimiter)
-	case InstallationScope:
-		return fmt.Sprintf("%s%s%s%s",
-			info.Team, delimiter,
-			info.Pipeline, delimiter)
-	case TeamScope:
-		return fmt.Sprintf("%s%s",
-			info.Pipeline, delimiter)
-	case AllTeamsScope:
-		return fmt.Sprintf("%s%s",
-			info.Installation, delimiter)
-	case AllPipelinesScope:
-		return fmt.Sprintf("%s%s%s%s",
-			info.Installation, delimiter,
-			info.Team, delimiter)
+// A copy of the info with the naming override, the info itself if there is no override
+func (info *ScopeInfo) WithNaming(naming ScopeComponents) *ScopeInfo {
+	if naming == nil {
+		return info
 	}
 
+	result := *info
+	result.Naming = naming
+	return &result
+}
+
+// The components the names of the resources of the scope are prefixed with
+func (info *ScopeInfo) Components(scope Scope) ScopeComponents {
+	if info.Naming != nil {
+		return info.Naming
+	}
+	return scope.Components()
+}
+
+func (info *ScopeInfo) component(component ScopeComponent) string {
+	switch component {
+	case InstallationComponent:
+		return string(info.Installation)
+	case TeamComponent:
+		return string(info.Team)
+	case PipelineComponent:
+		return string(info.Pipeline)
+	}
 	return ""
 }
+
+// The name of a resource of the scope, the non empty components and the name joined with the delimiter
+func (info *ScopeInfo) Name(scope Scope, delimiter string, name string) string {
+	var parts []string
+	for _, component := range info.Components(scope) {
+		if value := info.component(component); value != "" {
+			parts = append(parts, value)
+		}
+	}
+
+	if name != "" {
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, delimiter)
+}
+
+// The prefix of the names of the resources of the scope, ends with the delimiter unless it is empty
+func (info *ScopeInfo) Scope(scope Scope, delimiter string) string {
+	prefix := info.Name(scope, delimiter, "")
+	if prefix == "" {
+		return ""
+	}
+	return prefix + delimiter
+}
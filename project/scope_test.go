@@ -0,0 +1,143 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeName(t *testing.T) {
+	info := &ScopeInfo{
+		Pipeline:     "pipeline",
+		Team:         "team",
+		Installation: "inst",
+	}
+
+	tests := []struct {
+		scope  Scope
+		naming ScopeComponents
+		name   string
+		result string
+	}{
+		{IsolatedPipelineScope, nil, "tag", "inst-team-pipeline-tag"},
+		{IsolatedTeamScope, nil, "tag", "inst-team-tag"},
+		{IsolatedInstallationScope, nil, "tag", "inst-tag"},
+		{PublicScope, nil, "tag", "tag"},
+
+		{IsolatedPipelineScope, nil, "", "inst-team-pipeline"},
+		{IsolatedTeamScope, nil, "", "inst-team"},
+		{IsolatedInstallationScope, nil, "", "inst"},
+		{PublicScope, nil, "", ""},
+
+		{PublicScope, ScopeComponents{TeamComponent}, "tag", "team-tag"},
+		{IsolatedPipelineScope, ScopeComponents{PipelineComponent}, "tag", "pipeline-tag"},
+		{IsolatedPipelineScope, NoScopeComponents, "tag", "tag"},
+		{TeamScope, ScopeComponents{TeamComponent, InstallationComponent}, "", "team-inst"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.result, info.WithNaming(test.naming).Name(test.scope, "-", test.name),
+			"scope %d naming %v name %q", test.scope, test.naming, test.name)
+	}
+}
+
+// The existing scopes keep the names of the resources already deployed with them
+func TestScopeBaselineNames(t *testing.T) {
+	info := &ScopeInfo{
+		Pipeline:     "pipeline",
+		Team:         "team",
+		Installation: "inst",
+	}
+
+	tests := []struct {
+		scope  Scope
+		tag    string
+		prefix string
+	}{
+		{PipelineScope, "tag", ""},
+		{AllPipelinesScope, "inst-team-tag", "inst_team_"},
+		{TeamScope, "pipeline-tag", "pipeline_"},
+		{AllTeamsScope, "inst-tag", "inst_"},
+		{InstallationScope, "team-pipeline-tag", "team_pipeline_"},
+		{UniverseScope, "inst-team-pipeline-tag", "inst_team_pipeline_"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.tag, info.Name(test.scope, "-", "tag"), "scope %d", test.scope)
+		assert.Equal(t, test.prefix, info.Scope(test.scope, "_"), "scope %d", test.scope)
+	}
+}
+
+func TestScopePrefix(t *testing.T) {
+	info := &ScopeInfo{
+		Pipeline:     "pipeline",
+		Team:         "team",
+		Installation: "inst",
+	}
+
+	tests := []struct {
+		scope  Scope
+		result string
+	}{
+		{IsolatedPipelineScope, "inst_team_pipeline_"},
+		{IsolatedTeamScope, "inst_team_"},
+		{IsolatedInstallationScope, "inst_"},
+		{PublicScope, ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.result, info.Scope(test.scope, "_"), "scope %d", test.scope)
+	}
+}
+
+func TestScopeSkipsEmptyComponents(t *testing.T) {
+	info := &ScopeInfo{
+		Pipeline: "pipeline",
+	}
+
+	assert.Equal(t, "pipeline-tag", info.Name(IsolatedPipelineScope, "-", "tag"))
+	assert.Equal(t, "tag", info.Name(IsolatedTeamScope, "-", "tag"))
+	assert.Equal(t, "", info.Scope(IsolatedTeamScope, "-"))
+}
+
+func TestScopeSharedResourcesDoNotCollideAcrossTeams(t *testing.T) {
+	scopes := []Scope{IsolatedPipelineScope, IsolatedTeamScope}
+
+	for _, scope := range scopes {
+		first := &ScopeInfo{Pipeline: "pipeline", Team: "first", Installation: "inst"}
+		second := &ScopeInfo{Pipeline: "pipeline", Team: "second", Installation: "inst"}
+		assert.NotEqual(t, first.Name(scope, "-", "tag"), second.Name(scope, "-", "tag"), "scope %d", scope)
+	}
+}
+
+func TestResourceNamingOverride(t *testing.T) {
+	newPipeline := func(naming ScopeComponents) *Pipeline {
+		artifact := &Resource{
+			Name:   "artifact",
+			Type:   "graph-test",
+			Source: &testScopedSource{Bucket: "artifacts"},
+			Scope:  IsolatedPipelineScope,
+			Naming: naming,
+		}
+
+		pipeline := NewPipeline()
+		pipeline.Name = "pipeline"
+		pipeline.Jobs = Jobs{
+			&Job{
+				Name: "deploy",
+				Steps: ISteps{
+					&testStep{inputs: JobResources{pipeline.ResourceRegistry.JobResource(artifact, true, nil)}},
+				},
+			},
+		}
+		return pipeline
+	}
+
+	assert.Contains(t, saveToString(t, newPipeline(nil)), "key: installation/team/pipeline/artifact.tgz")
+	assert.Contains(t, saveToString(t, newPipeline(ScopeComponents{TeamComponent})), "key: team/artifact.tgz")
+	assert.Contains(t, saveToString(t, newPipeline(NoScopeComponents)), "key: artifact.tgz")
+
+	assert.NotEqual(t,
+		(&Resource{Type: "git", Naming: NoScopeComponents}).MustHash(),
+		(&Resource{Type: "git"}).MustHash())
+}
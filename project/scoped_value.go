@@ -0,0 +1,50 @@
+package project
+
+import (
+	"fmt"
+
+	"github.com/concourse-friends/concourse-builder/model"
+)
+
+// A task environment value that depends on the scope the pipeline is rendered in
+type IScopedValue interface {
+	ScopedValue(info *ScopeInfo) string
+}
+
+// Replaces the scoped values of the tasks of the step with their values in the scope
+func resolveScopedValues(step model.IStep, info *ScopeInfo) error {
+	switch step := step.(type) {
+	case *model.Task:
+		if step.Config == nil {
+			return nil
+		}
+
+		for name, value := range step.Config.Params {
+			scoped, ok := value.(IScopedValue)
+			if !ok {
+				continue
+			}
+
+			if info == nil {
+				return fmt.Errorf("Task %s has scoped value %s, but the scope of the render is unknown",
+					step.Task, name)
+			}
+			step.Config.Params[name] = scoped.ScopedValue(info)
+		}
+	case *model.Aggregation:
+		for _, aggregated := range step.Aggregate {
+			if err := resolveScopedValues(aggregated, info); err != nil {
+				return err
+			}
+		}
+	case *model.Do:
+		for _, done := range step.Do {
+			if err := resolveScopedValues(done, info); err != nil {
+				return err
+			}
+		}
+	case *model.Try:
+		return resolveScopedValues(step.Try, info)
+	}
+	return nil
+}
@@ -9,3 +9,43 @@ type IStep interface {
 }
 
 type ISteps []IStep
+
+// Implemented by the steps composed of other steps, they put the resources of all their steps
+type ICompositeStep interface {
+	OutputResources() ([]*Resource, error)
+}
+
+// All resources the step puts
+func stepOutputResources(step IStep) ([]*Resource, error) {
+	if composite, ok := step.(ICompositeStep); ok {
+		return composite.OutputResources()
+	}
+
+	output, err := step.OutputResource()
+	if err != nil || output == nil {
+		return nil, err
+	}
+	return []*Resource{output}, nil
+}
+
+// The resources the steps put
+func stepsOutputResources(steps ISteps) ([]*Resource, error) {
+	var outputs []*Resource
+	for _, step := range steps {
+		stepOutputs, err := stepOutputResources(step)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, stepOutputs...)
+	}
+	return outputs, nil
+}
+
+// The last resource the steps put, the steps put all with OutputResources
+func lastOutputResource(steps ISteps) (*Resource, error) {
+	outputs, err := stepsOutputResources(steps)
+	if err != nil || len(outputs) == 0 {
+		return nil, err
+	}
+	return outputs[len(outputs)-1], nil
+}
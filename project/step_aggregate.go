@@ -0,0 +1,48 @@
+package project
+
+import (
+	"github.com/concourse-friends/concourse-builder/model"
+)
+
+// Steps that run in parallel
+type AggregateStep struct {
+	Steps ISteps
+}
+
+func (as *AggregateStep) Model() (model.IStep, error) {
+	aggregation := &model.Aggregation{}
+
+	for _, step := range as.Steps {
+		modelStep, err := step.Model()
+		if err != nil {
+			return nil, err
+		}
+		aggregation.Aggregate = append(aggregation.Aggregate, modelStep)
+	}
+
+	return aggregation, nil
+}
+
+func (as *AggregateStep) InputResources() (JobResources, error) {
+	var resources JobResources
+
+	for _, step := range as.Steps {
+		stepResources, err := step.InputResources()
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, stepResources...)
+	}
+
+	return resources.Deduplicate(), nil
+}
+
+// The resource of the last put of the steps
+func (as *AggregateStep) OutputResource() (*Resource, error) {
+	return lastOutputResource(as.Steps)
+}
+
+// The resources of all puts of the steps
+func (as *AggregateStep) OutputResources() ([]*Resource, error) {
+	return stepsOutputResources(as.Steps)
+}
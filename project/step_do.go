@@ -0,0 +1,48 @@
+package project
+
+import (
+	"github.com/concourse-friends/concourse-builder/model"
+)
+
+// Steps that run one after another
+type DoStep struct {
+	Steps ISteps
+}
+
+func (ds *DoStep) Model() (model.IStep, error) {
+	do := &model.Do{}
+
+	for _, step := range ds.Steps {
+		modelStep, err := step.Model()
+		if err != nil {
+			return nil, err
+		}
+		do.Do = append(do.Do, modelStep)
+	}
+
+	return do, nil
+}
+
+func (ds *DoStep) InputResources() (JobResources, error) {
+	var resources JobResources
+
+	for _, step := range ds.Steps {
+		stepResources, err := step.InputResources()
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, stepResources...)
+	}
+
+	return resources.Deduplicate(), nil
+}
+
+// The resource of the last put of the steps
+func (ds *DoStep) OutputResource() (*Resource, error) {
+	return lastOutputResource(ds.Steps)
+}
+
+// The resources of all puts of the steps
+func (ds *DoStep) OutputResources() ([]*Resource, error) {
+	return stepsOutputResources(ds.Steps)
+}
@@ -19,6 +19,11 @@ type IOutput interface {
 	Path() string
 }
 
+// Implemented by the outputs the task writes to another path than the one of the tasks that get them
+type IOutputPath interface {
+	OutputPath() string
+}
+
 type ITaskInput interface {
 	OutputNames() []string
 }
@@ -42,12 +47,14 @@ type TaskStep struct {
 	Environment map[string]interface{}
 	Directory   ITaskDirectory
 	User        string
+	Tags        []string
 }
 
 func (ts *TaskStep) Model() (model.IStep, error) {
 	task := &model.Task{
 		Task:       model.TaskName(ts.Name),
 		Privileged: ts.Privileged,
+		Tags:       ts.Tags,
 		Config: &model.TaskConfig{
 			Platform: ts.Platform,
 			Run: &model.TaskRun{
@@ -136,9 +143,14 @@ func (ts *TaskStep) Model() (model.IStep, error) {
 	}
 
 	for _, output := range ts.Outputs {
+		path := output.Path()
+		if outputPath, ok := output.(IOutputPath); ok {
+			path = outputPath.OutputPath()
+		}
+
 		task.Config.Outputs = append(task.Config.Outputs, &model.TaskOutput{
 			Name: output.Name(),
-			Path: output.Path(),
+			Path: path,
 		})
 	}
 
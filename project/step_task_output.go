@@ -6,6 +6,10 @@ import (
 
 type TaskOutput struct {
 	Directory string
+
+	// Optional. Where the task writes the output if not in the Directory, a fixed path of a third party task
+	// for example. The tasks that get the output still find it in the Directory.
+	WrittenTo string
 }
 
 func (to *TaskOutput) Name() string {
@@ -15,3 +19,11 @@ func (to *TaskOutput) Name() string {
 func (to *TaskOutput) Path() string {
 	return to.Directory
 }
+
+// The path the task that produces the output writes it to
+func (to *TaskOutput) OutputPath() string {
+	if to.WrittenTo != "" {
+		return to.WrittenTo
+	}
+	return to.Directory
+}
@@ -30,6 +30,32 @@ type ImageSource struct {
 
 	// Optional. AWS secret key to use for acquiring ECR credentials.
 	AwsSecretAccessKey string `yaml:"aws_secret_access_key,omitempty"`
+
+	// Optional. AWS session token of temporary credentials.
+	AwsSessionToken string `yaml:"aws_session_token,omitempty"`
+
+	// Optional. AWS role assumed to acquire ECR credentials.
+	AwsRoleArn string `yaml:"aws_role_arn,omitempty"`
+
+	// Optional. The username to authenticate with.
+	Username string `yaml:",omitempty"`
+
+	// Optional. The password to authenticate with.
+	Password string `yaml:",omitempty"`
+
+	// Optional. Registries accessed over plain HTTP or with unverified certificates.
+	InsecureRegistries []string `yaml:"insecure_registries,omitempty"`
+
+	// Optional. CA certificates of the registries.
+	CaCerts []*ImageCaCert `yaml:"ca_certs,omitempty"`
+}
+
+type ImageCaCert struct {
+	// The registry domain the certificate is for
+	Domain string
+
+	// The PEM encoded certificate
+	Cert string
 }
 
 func (im ImageSource) ResourceName() project.ResourceName {
@@ -57,6 +83,9 @@ type ImagePutParams struct {
 	Build     string                 `yaml:",omitempty"`
 	BuildArgs map[string]interface{} `yaml:"build_args,omitempty"`
 	LoadBase  string                 `yaml:"load_base,omitempty"`
+
+	// Path to a file with whitespace separated tags pushed in addition to the tag of the source
+	AdditionalTags string `yaml:"additional_tags,omitempty"`
 }
 
 func init() {
@@ -22,6 +22,17 @@ type GitPullRequestSource struct {
 	AccessToken string `yaml:"access_token,omitempty"`
 }
 
+type GitPullRequestPutParams struct {
+	// The directory of the fetched pull request
+	Path string
+
+	// The status of the pull request commit: pending, success, failure or error
+	Status string
+
+	// The context of the status, statuses with different contexts are shown separately
+	Context string `yaml:",omitempty"`
+}
+
 // The pull request resource type
 var PullRequestResourceType = &project.ResourceType{
 	// The name
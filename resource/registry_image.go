@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+// Registry image resource type, it does not need privileged workers
+var RegistryImageResourceType = &project.ResourceType{
+	// The name
+	Name: "registry-image",
+
+	// The type
+	Type: model.SystemResourceTypeName,
+}
+
+// Registry image resource source
+type RegistryImageSource struct {
+	// Image repository
+	Repository string
+
+	// Image tag
+	Tag string `yaml:",omitempty"`
+
+	// Optional. AWS access key to use for acquiring ECR credentials.
+	AwsAccessKeyID string `yaml:"aws_access_key_id,omitempty"`
+
+	// Optional. AWS secret key to use for acquiring ECR credentials.
+	AwsSecretAccessKey string `yaml:"aws_secret_access_key,omitempty"`
+
+	// Optional. AWS region of the ECR registry.
+	AwsRegion string `yaml:"aws_region,omitempty"`
+
+	// Optional. AWS session token of temporary credentials.
+	AwsSessionToken string `yaml:"aws_session_token,omitempty"`
+
+	// Optional. AWS role assumed to acquire ECR credentials.
+	AwsRoleArn string `yaml:"aws_role_arn,omitempty"`
+
+	// Optional. The username to authenticate with.
+	Username string `yaml:",omitempty"`
+
+	// Optional. The password to authenticate with.
+	Password string `yaml:",omitempty"`
+
+	// Optional. PEM encoded CA certificates of the registry.
+	CaCerts []string `yaml:"ca_certs,omitempty"`
+
+	// Optional. The platform of the image fetched from a multi-platform tag, the one of the worker by default.
+	Platform *RegistryImagePlatform `yaml:",omitempty"`
+}
+
+type RegistryImagePlatform struct {
+	OS           string `yaml:"os"`
+	Architecture string
+}
+
+type RegistryImageGetParams struct {
+	// The format of the fetched image, rootfs or oci
+	Format       string `yaml:",omitempty"`
+	SkipDownload bool   `yaml:"skip_download,omitempty"`
+}
+
+type RegistryImagePutParams struct {
+	// The path to the OCI image tarball to push
+	Image string
+
+	// Path to a file with whitespace separated tags pushed in addition to the tag of the source
+	AdditionalTags string `yaml:"additional_tags,omitempty"`
+}
+
+func init() {
+	project.GlobalTypeRegistry.MustRegisterType(RegistryImageResourceType)
+}
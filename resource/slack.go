@@ -10,6 +10,29 @@ type SlackSource struct {
 	URL string
 }
 
+func (ss *SlackSource) ModelSource(scope project.Scope, info *project.ScopeInfo) interface{} {
+	return ss
+}
+
+type SlackPutParams struct {
+	// The message, the build metadata environment variables in it are expanded
+	Text string `yaml:",omitempty"`
+
+	// Overrides the channel of the webhook
+	Channel string `yaml:",omitempty"`
+
+	// Overrides the name of the webhook bot
+	Username string `yaml:",omitempty"`
+
+	// Overrides the icon of the webhook bot
+	IconEmoji string `yaml:"icon_emoji,omitempty"`
+	IconURL   string `yaml:"icon_url,omitempty"`
+}
+
+func (spp *SlackPutParams) ModelParams() interface{} {
+	return spp
+}
+
 // Slack resource type
 var SlackResourceType = &project.ResourceType{
 	// The name
@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/concourse-friends/concourse-builder/model"
@@ -10,7 +11,104 @@ import (
 // Time resource source
 type TimeSource struct {
 	// Lose interval between versions
-	Interval time.Duration
+	Interval model.Duration `yaml:",omitempty"`
+
+	// The time range in which the versions are produced, e.g. "2:00 AM" and "3:00 AM".
+	// They make sense only as pair.
+	Start string `yaml:",omitempty"`
+	Stop  string `yaml:",omitempty"`
+
+	// The week days in which the versions are produced, e.g. "Monday", all days if empty
+	Days []string `yaml:",omitempty"`
+
+	// The location of Start and Stop, e.g. "Europe/Sofia", UTC by default
+	Location string `yaml:",omitempty"`
+
+	// Produce a version as soon as the resource is checked, not only in the time range
+	InitialVersion bool `yaml:"initial_version,omitempty"`
+}
+
+// The layouts of the times of the time resource
+var timeLayouts = []string{
+	"3:04 PM",
+	"3PM",
+	"3 PM",
+	"15:04",
+	"1504",
+	"3:04 PM -0700",
+	"3PM -0700",
+	"3 PM -0700",
+	"15:04 -0700",
+	"1504 -0700",
+}
+
+func parseTime(value string) error {
+	for _, layout := range timeLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("Time %q has unknown format", value)
+}
+
+func (ts *TimeSource) Validate() error {
+	if (ts.Start == "") != (ts.Stop == "") {
+		return fmt.Errorf("Time source Start and Stop make sense only as pair")
+	}
+
+	if ts.Interval == 0 && ts.Start == "" {
+		return fmt.Errorf("Time source needs Interval or Start and Stop")
+	}
+
+	for _, value := range []string{ts.Start, ts.Stop} {
+		if value == "" {
+			continue
+		}
+		if err := parseTime(value); err != nil {
+			return err
+		}
+	}
+
+	for _, day := range ts.Days {
+		known := false
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			known = known || weekday.String() == day
+		}
+		if !known {
+			return fmt.Errorf("Time source day %q is not a week day", day)
+		}
+	}
+
+	if ts.Location != "" {
+		if _, err := time.LoadLocation(ts.Location); err != nil {
+			return fmt.Errorf("Time source location %q is unknown: %s", ts.Location, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (ts *TimeSource) ModelSource(scope project.Scope, info *project.ScopeInfo) interface{} {
+	return ts
+}
+
+// Once a day, between 2 and 3 AM in the location
+func NightlyTimeSource(location string) *TimeSource {
+	return &TimeSource{
+		Start:    "2:00 AM",
+		Stop:     "3:00 AM",
+		Location: location,
+	}
+}
+
+// A time resource of the pipeline, the jobs it triggers run on its schedule
+func TimeResource(name project.ResourceName, source *TimeSource) *project.Resource {
+	return &project.Resource{
+		Name:   name,
+		Type:   TimeResourceType.Name,
+		Scope:  project.PipelineScope,
+		Source: source,
+	}
 }
 
 // The time resource type
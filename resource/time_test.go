@@ -0,0 +1,48 @@
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeSourceValidate(t *testing.T) {
+	assert.NoError(t, NightlyTimeSource("Europe/Sofia").Validate())
+	assert.NoError(t, (&TimeSource{Interval: model.Duration(time.Hour), Days: []string{"Monday"}}).Validate())
+	assert.NoError(t, (&TimeSource{Start: "22:00 +0200", Stop: "11PM"}).Validate())
+
+	tests := []struct {
+		name   string
+		source *TimeSource
+		result string
+	}{
+		{
+			"no schedule",
+			&TimeSource{},
+			"Time source needs Interval or Start and Stop",
+		},
+		{
+			"start without stop",
+			&TimeSource{Start: "2:00 AM"},
+			"Time source Start and Stop make sense only as pair",
+		},
+		{
+			"invalid time",
+			&TimeSource{Start: "2 o'clock", Stop: "3:00 AM"},
+			`Time "2 o'clock" has unknown format`,
+		},
+		{
+			"invalid day",
+			&TimeSource{Interval: model.Duration(time.Hour), Days: []string{"Mon"}},
+			`Time source day "Mon" is not a week day`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.EqualError(t, test.source.Validate(), test.result, test.name)
+	}
+
+	assert.Error(t, (&TimeSource{Interval: model.Duration(time.Hour), Location: "Nowhere/City"}).Validate())
+}
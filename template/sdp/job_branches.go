@@ -7,6 +7,7 @@ import (
 	"github.com/concourse-friends/concourse-builder/model"
 	"github.com/concourse-friends/concourse-builder/project"
 	"github.com/concourse-friends/concourse-builder/resource"
+	"github.com/concourse-friends/concourse-builder/template/sdp_branch"
 	"github.com/jinzhu/copier"
 )
 
@@ -19,9 +20,15 @@ type BranchesJobArgs struct {
 	TargetGitRepo           *primitive.GitRepo
 	Environment             map[string]interface{}
 	GenerateProjectLocation project.IRun
+
+	// Remove the branch pipelines without an owner too, the ones deployed before the pipelines were marked
+	AdoptUnmarkedPipelines bool
 }
 
-func taskObtainBranches(args *BranchesJobArgs, branchesDir *project.TaskOutput) *project.TaskStep {
+// The regular expression of the names of the branch pipelines
+const branchPipelinesRegex = ".*-sdpb$"
+
+func taskObtainBranches(args *BranchesJobArgs, branchesDir *project.TaskOutput, trigger bool) *project.TaskStep {
 	gitImageJobArgs := &library.GitImageJobArgs{}
 	copier.Copy(gitImageJobArgs, args)
 
@@ -38,7 +45,7 @@ func taskObtainBranches(args *BranchesJobArgs, branchesDir *project.TaskOutput)
 		},
 	}
 
-	targetGitJobResource := args.ResourceRegistry.JobResource(targetGitResource, true, nil)
+	targetGitJobResource := args.ResourceRegistry.JobResource(targetGitResource, trigger, nil)
 
 	environment := map[string]interface{}{
 		"GIT_REPO_DIR": &primitive.Location{
@@ -147,11 +154,16 @@ func taskRemoveNotNeededPipelines(args *BranchesJobArgs, pipelinesDir *project.T
 			"PIPELINES": &primitive.Location{
 				Volume: pipelinesDir,
 			},
-			"BRANCHES_DIR":   branchesDir.Path(),
-			"PIPELINE_REGEX": ".*-sdpb$",
+			"BRANCHES_DIR":       branchesDir.Path(),
+			"PIPELINE_REGEX":     branchPipelinesRegex,
+			"PIPELINE_GENERATOR": sdpBranch.Generator(args.TargetGitRepo),
 		},
 	}
 
+	if args.AdoptUnmarkedPipelines {
+		task.Environment["ADOPT_UNMARKED"] = "true"
+	}
+
 	args.Concourse.Environment(task.Environment)
 
 	return task
@@ -162,7 +174,7 @@ func BranchesJob(args *BranchesJobArgs) *project.Job {
 		Directory: "branches",
 	}
 
-	taskObtainBranches := taskObtainBranches(args, branchesDir)
+	taskObtainBranches := taskObtainBranches(args, branchesDir, true)
 
 	pipelinesDir := &project.TaskOutput{
 		Directory: "pipelines",
@@ -187,3 +199,15 @@ func BranchesJob(args *BranchesJobArgs) *project.Job {
 
 	return branchesJob
 }
+
+// The steps that prepare the pipelines of the live branches, the same the branches job prepares
+func livePipelinesSteps(args *BranchesJobArgs, pipelinesDir *project.TaskOutput) project.ISteps {
+	branchesDir := &project.TaskOutput{
+		Directory: "branches",
+	}
+
+	return project.ISteps{
+		taskObtainBranches(args, branchesDir, false),
+		taskPreparePipelines(args, branchesDir, pipelinesDir),
+	}
+}
@@ -0,0 +1,45 @@
+package sdp
+
+import (
+	"time"
+
+	"github.com/concourse-friends/concourse-builder/library"
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+// Optionally implemented by the specifications which images are tagged per branch pipeline
+type ImagePruningSpecification interface {
+	ImagePruning(resourceRegistry *project.ResourceRegistry) (*ImagePruning, error)
+}
+
+type ImagePruning struct {
+	// The images which tags of removed branch pipelines are pruned
+	Images []*project.Resource
+
+	// Only print the tags that would be pruned
+	DryRun bool
+
+	// How often the tags are pruned, once a day by default
+	Interval time.Duration
+}
+
+// The job that prunes the image tags of the branch pipelines of removed branches
+func PruneImagesJob(args *BranchesJobArgs, linuxImage *project.Resource, pruning *ImagePruning) (*project.Job, error) {
+	pipelinesDir := &project.TaskOutput{
+		Directory: "pipelines",
+	}
+
+	return library.PruneImagesJob(&library.PruneImagesJobArgs{
+		LinuxImageResource:  linuxImage,
+		ConcourseBuilderGit: args.ConcourseBuilderGit,
+		ImageRegistry:       args.ImageRegistry,
+		ResourceRegistry:    args.ResourceRegistry,
+		Concourse:           args.Concourse,
+		Images:              pruning.Images,
+		PipelinesSteps:      livePipelinesSteps(args, pipelinesDir),
+		Pipelines:           pipelinesDir,
+		PipelineRegex:       branchPipelinesRegex,
+		DryRun:              pruning.DryRun,
+		Interval:            pruning.Interval,
+	})
+}
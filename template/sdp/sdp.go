@@ -26,6 +26,12 @@ type Specification interface {
 	MaintenanceJobs(resourceRegistry *project.ResourceRegistry, gitResource *project.Resource) (project.Jobs, error)
 }
 
+// Optionally implemented by the specifications which branch pipelines were deployed before the pipelines
+// were marked with their owner. The unmarked branch pipelines are removed as if they were owned.
+type AdoptionSpecification interface {
+	AdoptUnmarkedPipelines() bool
+}
+
 const BranchesFileEnvVar = "BRANCHES_FILE"
 
 func BootstrapBranches() ([]string, error) {
@@ -57,19 +63,27 @@ func GenerateProject(specification Specification) (*project.Project, error) {
 			log.Printf("Branch %s does not fit the expected name protocol", branch)
 			continue
 		}
-		log.Printf("Preparing pipeline for branch %s", branch)
-
-		branchSpecification := &BranchBootstrapSpecification{
-			Specification: specification,
-			TargetBranch:  branch,
+		pipelineBranches := []*primitive.GitBranch{branch}
+		if pullRequestSpecification, ok := specification.(sdpBranch.PullRequestSpecification); ok &&
+			pullRequestSpecification.VerifyPullRequests(branch) {
+			pipelineBranches = append(pipelineBranches, branch.PrBranch())
 		}
 
-		project, err := sdpBranch.GenerateBootstrapProject(branchSpecification)
-		if err != nil {
-			return nil, err
-		}
+		for _, pipelineBranch := range pipelineBranches {
+			log.Printf("Preparing pipeline for branch %s", pipelineBranch)
+
+			branchSpecification := &BranchBootstrapSpecification{
+				Specification: specification,
+				TargetBranch:  pipelineBranch,
+			}
 
-		prj.Pipelines = append(prj.Pipelines, project.Pipelines...)
+			project, err := sdpBranch.GenerateBootstrapProject(branchSpecification)
+			if err != nil {
+				return nil, err
+			}
+
+			prj.Pipelines = append(prj.Pipelines, project.Pipelines...)
+		}
 	}
 
 	concourseBuilderGit, err := specification.ConcourseBuilderGit()
@@ -121,7 +135,7 @@ func GenerateProject(specification Specification) (*project.Project, error) {
 		return nil, err
 	}
 
-	mainPipeline.Name = project.ConvertToPipelineName(targetGit.FriendlyName() + "-sdp")
+	mainPipeline.Name = project.PipelineName(sdpBranch.Generator(targetGit))
 
 	if !concourseBuilderBranch.IsImage() {
 		mainPipeline.ReuseFromPipeline = append(mainPipeline.ReuseFromPipeline, concourseBuilderPipeline)
@@ -158,7 +172,7 @@ func GenerateProject(specification Specification) (*project.Project, error) {
 		GenerateProjectLocation: generateProjectLocation,
 	})
 
-	branchesJob := BranchesJob(&BranchesJobArgs{
+	branchesJobArgs := &BranchesJobArgs{
 		ConcourseBuilderGit:     concourseBuilderGit,
 		ImageRegistry:           imageRegistry,
 		GoImage:                 goImage,
@@ -167,7 +181,13 @@ func GenerateProject(specification Specification) (*project.Project, error) {
 		TargetGitRepo:           targetGit,
 		Environment:             environment,
 		GenerateProjectLocation: generateProjectLocation,
-	})
+	}
+
+	if adoptionSpecification, ok := specification.(AdoptionSpecification); ok {
+		branchesJobArgs.AdoptUnmarkedPipelines = adoptionSpecification.AdoptUnmarkedPipelines()
+	}
+
+	branchesJob := BranchesJob(branchesJobArgs)
 
 	mainPipeline.Jobs = project.Jobs{
 		selfUpdateJob,
@@ -187,12 +207,32 @@ func GenerateProject(specification Specification) (*project.Project, error) {
 		return nil, err
 	}
 
+	if pruningSpecification, ok := specification.(ImagePruningSpecification); ok {
+		pruning, err := pruningSpecification.ImagePruning(mainPipeline.ResourceRegistry)
+		if err != nil {
+			return nil, err
+		}
+
+		if pruning != nil {
+			pruneImagesJob, err := PruneImagesJob(branchesJobArgs, linuxImage, pruning)
+			if err != nil {
+				return nil, err
+			}
+			maintenanceJobs = append(maintenanceJobs, pruneImagesJob)
+		}
+	}
+
 	for _, job := range maintenanceJobs {
 		job.AddToGroup(maintenanceGroup)
 		job.AddJobToRunAfter(selfUpdateJob)
 	}
 	mainPipeline.Jobs = append(mainPipeline.Jobs, maintenanceJobs...)
 
+	err = sdpBranch.NotifyFailures(specification, mainPipeline)
+	if err != nil {
+		return nil, err
+	}
+
 	prj.Pipelines = append(prj.Pipelines, mainPipeline)
 
 	return prj, nil
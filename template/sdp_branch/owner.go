@@ -0,0 +1,21 @@
+package sdpBranch
+
+import (
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/project"
+)
+
+// The generator of the branch pipelines of a target repo, it is the name of the sdp pipeline
+// that creates and removes them
+func Generator(targetGit *primitive.GitRepo) string {
+	return string(project.ConvertToPipelineName(targetGit.FriendlyName() + "-sdp"))
+}
+
+// The owner of the pipeline of a branch of the target repo
+func Owner(targetGit *primitive.GitRepo, branch *primitive.GitBranch) *project.PipelineOwner {
+	return &project.PipelineOwner{
+		Generator: Generator(targetGit),
+		Repo:      targetGit.URI,
+		Branch:    branch.CanonicalName(),
+	}
+}
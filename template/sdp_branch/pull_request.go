@@ -0,0 +1,153 @@
+package sdpBranch
+
+import (
+	"fmt"
+
+	"github.com/concourse-friends/concourse-builder/library"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+)
+
+// Optionally implemented by the specifications which verify the pull requests into their branches.
+// The pull requests into a branch are verified by the pipeline of its pull request branch.
+type PullRequestSpecification interface {
+	// If the pull requests into the branch are verified
+	VerifyPullRequests(branch *primitive.GitBranch) bool
+
+	// Github access token the pull requests are read and their statuses updated with
+	PullRequestAccessToken() (string, error)
+}
+
+// Optionally implemented by the specifications which verify jobs verify the source they are given.
+// The source is the git branch, or the pull requests into the target branch for a pull request branch.
+// The verify jobs of the other specifications verify the pull requests in place of the git resource
+// of the branch, <branch>-git.
+type SourceVerifySpecification interface {
+	VerifySourceJobs(resourceRegistry *project.ResourceRegistry, source *project.JobResource) (project.Jobs, error)
+}
+
+// The verify jobs of the branch, the ones of a pull request branch report their statuses to the pull requests
+func sourceVerifyJobs(specification Specification, resourceRegistry *project.ResourceRegistry) (project.Jobs, error) {
+	branch := specification.Branch()
+
+	sourceSpecification, ok := specification.(SourceVerifySpecification)
+	if !ok && !branch.IsPr() {
+		return specification.VerifyJobs(resourceRegistry)
+	}
+
+	source, err := SourceJobResource(specification, resourceRegistry)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs project.Jobs
+	if ok {
+		jobs, err = sourceSpecification.VerifySourceJobs(resourceRegistry, source)
+	} else {
+		jobs, err = substitutedVerifyJobs(specification, resourceRegistry, source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if branch.IsPr() {
+		addPullRequestStatuses(resourceRegistry, source, jobs)
+	}
+	return jobs, nil
+}
+
+// The verify jobs with the git resource of the branch substituted with the source
+func substitutedVerifyJobs(specification Specification, resourceRegistry *project.ResourceRegistry,
+	source *project.JobResource) (project.Jobs, error) {
+
+	targetGit, err := specification.TargetGitRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	gitResource := branchGitResource(targetGit, specification.Branch())
+	err = resourceRegistry.Substitute(gitResource.Name, resourceRegistry.MustGetResource(source.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	return specification.VerifyJobs(resourceRegistry)
+}
+
+// The git resource of the branch of the target repo
+func branchGitResource(targetGit *primitive.GitRepo, branch *primitive.GitBranch) *project.Resource {
+	return &project.Resource{
+		Name: project.ConvertToResourceName(branch.FriendlyName() + "-git"),
+		Type: resource.GitResourceType.Name,
+		Source: &library.GitSource{
+			Repo: targetGit,
+			Branch: &primitive.GitBranch{
+				Name: branch.FriendlyName(),
+			},
+		},
+	}
+}
+
+// The resource the verify jobs verify. For a pull request branch these are the pull requests
+// into its target branch, each version of them triggers the jobs; the git branch otherwise.
+func SourceJobResource(specification BootstrapSpecification, resourceRegistry *project.ResourceRegistry) (*project.JobResource, error) {
+	targetGit, err := specification.TargetGitRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	branch := specification.Branch()
+	if !branch.IsPr() {
+		return resourceRegistry.JobResource(branchGitResource(targetGit, branch), true, nil), nil
+	}
+
+	pullRequestSpecification, ok := specification.(PullRequestSpecification)
+	if !ok {
+		return nil, fmt.Errorf("Branch %s is a pull request branch, but the specification does not verify pull requests",
+			branch.CanonicalName())
+	}
+
+	accessToken, err := pullRequestSpecification.PullRequestAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	target := branch.PrTarget()
+	pullRequestResource := &project.Resource{
+		Name: project.ConvertToResourceName(target.FriendlyName() + "-pr"),
+		Type: resource.PullRequestResourceType.Name,
+		Source: &library.PullRequestSource{
+			Repo:        targetGit,
+			Base:        target,
+			AccessToken: accessToken,
+		},
+	}
+
+	pullRequest := resourceRegistry.JobResource(pullRequestResource, true, nil)
+	pullRequest.Version = "every"
+	return pullRequest, nil
+}
+
+// Makes the jobs report their progress as statuses of the verified pull request commit
+func addPullRequestStatuses(resourceRegistry *project.ResourceRegistry, pullRequest *project.JobResource,
+	jobs project.Jobs) {
+
+	for _, job := range jobs {
+		context := string(job.Name)
+		status := func(status library.PullRequestStatus) project.IStep {
+			return &project.PutStep{
+				Resource: resourceRegistry.MustGetResource(pullRequest.Name),
+				Params: &library.PullRequestPutParams{
+					PullRequest: pullRequest,
+					Status:      status,
+					Context:     context,
+				},
+			}
+		}
+
+		job.Steps = append(project.ISteps{status(library.PullRequestPending)}, job.Steps...)
+		job.AddOnSuccess(status(library.PullRequestSuccess))
+		job.AddOnFailure(status(library.PullRequestFailure))
+	}
+}
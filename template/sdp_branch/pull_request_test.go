@@ -0,0 +1,193 @@
+package sdpBranch
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/concourse-friends/concourse-builder/library"
+	"github.com/concourse-friends/concourse-builder/library/image"
+	"github.com/concourse-friends/concourse-builder/library/primitive"
+	"github.com/concourse-friends/concourse-builder/model"
+	"github.com/concourse-friends/concourse-builder/project"
+	"github.com/concourse-friends/concourse-builder/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSpecification struct {
+	branch string
+}
+
+func (s *testSpecification) Branch() *primitive.GitBranch {
+	return &primitive.GitBranch{Name: s.branch}
+}
+
+func (s *testSpecification) TargetGitRepo() (*primitive.GitRepo, error) {
+	return &primitive.GitRepo{
+		URI:        "git@github.com:org/repo.git",
+		PrivateKey: "private-key",
+	}, nil
+}
+
+func (s *testSpecification) Concourse() (*primitive.Concourse, error) {
+	return &primitive.Concourse{URL: "http://concourse.com"}, nil
+}
+
+func (s *testSpecification) DeployImageRegistry() (*image.Registry, error) {
+	return &image.Registry{
+		Domain:             "123.dkr.ecr.eu-west-1.amazonaws.com",
+		AwsAccessKeyId:     "key",
+		AwsSecretAccessKey: "secret",
+	}, nil
+}
+
+func (s *testSpecification) LinuxImage(resourceRegistry *project.ResourceRegistry) (*project.Resource, error) {
+	return image.Ubuntu, nil
+}
+
+func (s *testSpecification) GoImage(resourceRegistry *project.ResourceRegistry) (*project.Resource, error) {
+	return image.Go, nil
+}
+
+func (s *testSpecification) ConcourseBuilderGit() (*project.Resource, error) {
+	return &project.Resource{
+		Name: library.ConcourseBuilderGitName,
+		Type: resource.GitResourceType.Name,
+		Source: &library.GitSource{
+			Repo:   &primitive.GitRepo{URI: "git@github.com:concourse-friends/concourse-builder.git"},
+			Branch: &primitive.GitBranch{Name: "master"},
+		},
+	}, nil
+}
+
+func (s *testSpecification) GenerateProjectLocation(resourceRegistry *project.ResourceRegistry) (project.IRun, error) {
+	return &primitive.Location{
+		Volume:       &primitive.Directory{Root: "/bin"},
+		RelativePath: "generate",
+	}, nil
+}
+
+func (s *testSpecification) Environment() (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+
+func (s *testSpecification) InitializeAdditionalSharedResourcesArgs(args *library.SharedResourcesArgs) error {
+	return nil
+}
+
+func (s *testSpecification) SharedJobs(resourceRegistry *project.ResourceRegistry, gitResource *project.Resource) (project.Jobs, error) {
+	return nil, nil
+}
+
+func (s *testSpecification) ModifyJobs(resourceRegistry *project.ResourceRegistry) (project.Jobs, error) {
+	return nil, nil
+}
+
+// Verifies the git resource of the branch, like the specifications that do not verify sources
+func (s *testSpecification) VerifyJobs(resourceRegistry *project.ResourceRegistry) (project.Jobs, error) {
+	targetGit, err := s.TargetGitRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	git := &project.Resource{
+		Name: project.ConvertToResourceName(s.Branch().FriendlyName() + "-git"),
+		Type: resource.GitResourceType.Name,
+		Source: &library.GitSource{
+			Repo:   targetGit,
+			Branch: &primitive.GitBranch{Name: s.branch},
+		},
+	}
+
+	return testVerifyJobs(resourceRegistry, resourceRegistry.JobResource(git, true, nil)), nil
+}
+
+func (s *testSpecification) MaintenanceJobs(resourceRegistry *project.ResourceRegistry, gitResource *project.Resource) (project.Jobs, error) {
+	return nil, nil
+}
+
+func (s *testSpecification) VerifyPullRequests(branch *primitive.GitBranch) bool {
+	return branch.IsMaster()
+}
+
+func (s *testSpecification) PullRequestAccessToken() (string, error) {
+	return "token", nil
+}
+
+// A specification which verify jobs verify the source they are given
+type sourceTestSpecification struct {
+	*testSpecification
+}
+
+func (s *sourceTestSpecification) VerifySourceJobs(resourceRegistry *project.ResourceRegistry,
+	source *project.JobResource) (project.Jobs, error) {
+
+	return testVerifyJobs(resourceRegistry, source), nil
+}
+
+func testVerifyJobs(resourceRegistry *project.ResourceRegistry, source *project.JobResource) project.Jobs {
+	return project.Jobs{
+		&project.Job{
+			Name: "test",
+			Steps: project.ISteps{
+				&project.TaskStep{
+					Platform: model.LinuxPlatform,
+					Name:     "test",
+					Image:    resourceRegistry.JobResource(image.Ubuntu, true, nil),
+					Run: &primitive.Location{
+						Volume:       source,
+						RelativePath: "test.sh",
+					},
+				},
+			},
+		},
+	}
+}
+
+func mainPipeline(t *testing.T, specification Specification) string {
+	prj, err := GenerateProject(specification)
+	require.NoError(t, err)
+
+	pipeline := prj.Pipelines[len(prj.Pipelines)-1]
+	assert.Equal(t, project.PipelineName(specification.Branch().FriendlyName()+"-sdpb"), pipeline.Name)
+
+	yml := &bytes.Buffer{}
+	require.NoError(t, pipeline.Save("team", "installation", yml))
+	return yml.String()
+}
+
+func TestPullRequestBranchVerifiesPullRequests(t *testing.T) {
+	rendered := mainPipeline(t, &sourceTestSpecification{&testSpecification{branch: "master-pr"}})
+
+	assert.Contains(t, rendered, "- name: master-pr\n  type: pull-request\n")
+	assert.Contains(t, rendered, "    base: master\n")
+	assert.Contains(t, rendered, "- get: master-pr\n      trigger: true\n      version: every\n")
+	assert.Contains(t, rendered, "path: master-pr/test.sh")
+	assert.Contains(t, rendered, "status: pending")
+	assert.Contains(t, rendered, "status: success")
+	assert.Contains(t, rendered, "status: failure")
+	assert.NotContains(t, rendered, "master-pr-git")
+}
+
+func TestPullRequestBranchSubstitutesGitOfVerifyJobs(t *testing.T) {
+	rendered := mainPipeline(t, &testSpecification{branch: "master-pr"})
+
+	assert.Contains(t, rendered, "- name: master-pr\n  type: pull-request\n")
+	assert.Contains(t, rendered, "- get: master-pr\n      trigger: true\n      version: every\n")
+	assert.Contains(t, rendered, "path: master-pr/test.sh")
+	assert.Contains(t, rendered, "status: pending")
+	assert.NotContains(t, rendered, "master-pr-git")
+}
+
+func TestBranchVerifiesGit(t *testing.T) {
+	for _, specification := range []Specification{
+		&testSpecification{branch: "master"},
+		&sourceTestSpecification{&testSpecification{branch: "master"}},
+	} {
+		rendered := mainPipeline(t, specification)
+
+		assert.Contains(t, rendered, "- name: master-git\n  type: git\n")
+		assert.Contains(t, rendered, "path: master-git/test.sh")
+		assert.NotContains(t, rendered, "pull-request")
+	}
+}
@@ -25,6 +25,13 @@ func GenerateBootstrapProject(specification BootstrapSpecification) (*project.Pr
 	mainPipeline.AllJobsGroup = project.AllJobsGroupFirst
 	mainPipeline.Name = project.ConvertToPipelineName(specification.Branch().FriendlyName() + "-sdpb")
 
+	targetGit, err := specification.TargetGitRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	mainPipeline.Owner = Owner(targetGit, specification.Branch())
+
 	linuxImage, err := specification.LinuxImage(mainPipeline.ResourceRegistry)
 	if err != nil {
 		return nil, err
@@ -17,6 +17,42 @@ type Specification interface {
 	MaintenanceJobs(resourceRegistry *project.ResourceRegistry, gitResource *project.Resource) (project.Jobs, error)
 }
 
+// Optionally implemented by the specifications that notify a channel about every failed build of the pipeline
+type FailureNotificationSpecification interface {
+	FailureNotification() (*library.SlackNotification, error)
+}
+
+// Optionally implemented by the specifications which maintenance jobs run on a schedule, e.g. nightly,
+// instead of after the self update
+type MaintenanceScheduleSpecification interface {
+	MaintenanceSchedule() (*resource.TimeSource, error)
+}
+
+// The schedule of the maintenance jobs, nil if they run after the self update
+func maintenanceSchedule(specification interface{}) (*resource.TimeSource, error) {
+	scheduleSpecification, ok := specification.(MaintenanceScheduleSpecification)
+	if !ok {
+		return nil, nil
+	}
+
+	return scheduleSpecification.MaintenanceSchedule()
+}
+
+// Notifies about the failed builds of the jobs of the pipeline if the specification asks so
+func NotifyFailures(specification interface{}, pipeline *project.Pipeline) error {
+	notificationSpecification, ok := specification.(FailureNotificationSpecification)
+	if !ok {
+		return nil
+	}
+
+	notification, err := notificationSpecification.FailureNotification()
+	if err != nil || notification == nil {
+		return err
+	}
+
+	return library.NotifyPipelineFailures(pipeline, notification)
+}
+
 func addPipelineResource(
 	mainPipeline *project.Pipeline,
 	selfUpdateJob *project.Job,
@@ -133,6 +169,7 @@ func GenerateProject(specification Specification) (*project.Project, error) {
 	mainPipeline := project.NewPipeline()
 	mainPipeline.AllJobsGroup = project.AllJobsGroupFirst
 	mainPipeline.Name = project.ConvertToPipelineName(specification.Branch().FriendlyName() + "-sdpb")
+	mainPipeline.Owner = Owner(targetGit, specification.Branch())
 
 	if !concourseBuilderBranch.IsImage() {
 		mainPipeline.ReuseFromPipeline = append(mainPipeline.ReuseFromPipeline, concourseBuilderPipeline)
@@ -192,7 +229,7 @@ func GenerateProject(specification Specification) (*project.Project, error) {
 		Name: "verify",
 	}
 
-	verifyJobs, err := specification.VerifyJobs(mainPipeline.ResourceRegistry)
+	verifyJobs, err := sourceVerifyJobs(specification, mainPipeline.ResourceRegistry)
 	if err != nil {
 		return nil, err
 	}
@@ -220,13 +257,27 @@ func GenerateProject(specification Specification) (*project.Project, error) {
 			return nil, err
 		}
 
+		schedule, err := maintenanceSchedule(specification)
+		if err != nil {
+			return nil, err
+		}
+
 		for _, job := range maintenanceJobs {
 			job.AddToGroup(maintenanceGroup)
-			job.AddJobToRunAfter(selfUpdateJob)
+			if schedule != nil {
+				job.RunOnSchedule(mainPipeline.ResourceRegistry, resource.TimeResource("maintenance-schedule", schedule))
+			} else {
+				job.AddJobToRunAfter(selfUpdateJob)
+			}
 		}
 		mainPipeline.Jobs = append(mainPipeline.Jobs, maintenanceJobs...)
 	}
 
+	err = NotifyFailures(specification, mainPipeline)
+	if err != nil {
+		return nil, err
+	}
+
 	prj.Pipelines = append(prj.Pipelines, mainPipeline)
 
 	return prj, nil